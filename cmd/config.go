@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"github.com/rovergulf/busybox/handler"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// configCmd groups configuration inspection commands
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect busybox configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print effective configuration resolved from config file, env and defaults as YAML",
+	Long: `Print effective configuration resolved from config file, env and defaults as YAML.
+Secret values are redacted the same way as /config endpoint does`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return writeYAML(cmd.OutOrStdout(), new(handler.Handler).EffectiveConfig())
+	},
+}
+
+var configDefaultsCmd = &cobra.Command{
+	Use:   "defaults",
+	Short: "Print all configuration keys with default values as YAML",
+	Long: `Print all configuration keys with default values as YAML.
+Keys decided by env, e.g. trace_sample_ratio, are printed with defaults of configured env`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defaults := make(map[string]any)
+		rootCmd.Flags().VisitAll(func(f *pflag.Flag) {
+			defaults[strings.ReplaceAll(f.Name, "-", "_")] = flagDefault(f)
+		})
+		for key, value := range new(handler.Handler).EnvDefaults() {
+			defaults[key] = value
+		}
+		return writeYAML(cmd.OutOrStdout(), defaults)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd, configDefaultsCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// flagDefault converts flag default value into its type, so it is written to YAML as is
+func flagDefault(f *pflag.Flag) any {
+	switch f.Value.Type() {
+	case "bool":
+		value, _ := strconv.ParseBool(f.DefValue)
+		return value
+	case "int", "int64":
+		value, _ := strconv.ParseInt(f.DefValue, 10, 64)
+		return value
+	case "float64":
+		value, _ := strconv.ParseFloat(f.DefValue, 64)
+		return value
+	case "stringSlice":
+		values := []string{}
+		if list := strings.Trim(f.DefValue, "[]"); len(list) > 0 {
+			values = strings.Split(list, ",")
+		}
+		return values
+	case "stringToString":
+		values := map[string]string{}
+		for _, pair := range strings.Split(strings.Trim(f.DefValue, "[]"), ",") {
+			if name, value, ok := strings.Cut(pair, "="); ok {
+				values[name] = value
+			}
+		}
+		return values
+	default:
+		return f.DefValue
+	}
+}
+
+func writeYAML(w io.Writer, v any) error {
+	encoder := yaml.NewEncoder(w)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
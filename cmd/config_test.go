@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadRemoteConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/busybox.yaml":
+			w.Write([]byte("max_delay: 5s\n"))
+		case "/busybox.json":
+			w.Write([]byte(`{"max_redirects": 3}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer func() {
+		// drop remote config values, keeping flags bound
+		viper.SetConfigType("yaml")
+		viper.ReadConfig(strings.NewReader(""))
+	}()
+
+	if err := readRemoteConfig(server.URL + "/busybox.yaml"); err != nil {
+		t.Fatalf("Unable to read remote config: %s", err)
+	}
+	if delay := viper.GetDuration("max_delay"); delay != 5*time.Second {
+		t.Errorf("Expected max_delay from remote yaml config, got: %s", delay)
+	}
+
+	if err := readRemoteConfig(server.URL + "/busybox.json"); err != nil {
+		t.Fatalf("Unable to read remote config: %s", err)
+	}
+	if redirects := viper.GetInt("max_redirects"); redirects != 3 {
+		t.Errorf("Expected max_redirects from remote json config, got: %d", redirects)
+	}
+
+	if err := readRemoteConfig(server.URL + "/missing.yaml"); err == nil {
+		t.Errorf("Expected error for non-200 response")
+	}
+}
+
+func TestConfigDefaultsCommand(t *testing.T) {
+	var out strings.Builder
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"config", "defaults"})
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"max_redirects: 20", "auth_password: \"\"", "disabled_routes: []", "trace_sample_ratio: 1", "cors_allow_credentials: true"} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("expected %q in defaults output:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestConfigShowRedactsSecrets(t *testing.T) {
+	viper.Set("auth_password", "secret")
+	defer viper.Set("auth_password", "")
+
+	var out strings.Builder
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"config", "show"})
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(out.String(), "secret") || !strings.Contains(out.String(), "auth_password: '***'") {
+		t.Errorf("expected redacted auth_password in output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"github.com/rovergulf/busybox/handler"
+	"github.com/spf13/cobra"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"time"
+)
+
+const (
+	defaultProbeTimeout = 10 * time.Second
+)
+
+var probeOptions struct {
+	method   string
+	headers  []string
+	data     string
+	timeout  time.Duration
+	insecure bool
+	json     bool
+}
+
+// probeCmd sends a single request to target and reports response and its timing breakdown
+var probeCmd = &cobra.Command{
+	Use:   "probe <url>",
+	Short: "Send HTTP request to target and print response status, timing and headers",
+	Long: `Send HTTP request to target and print response status, timing breakdown
+(DNS lookup, connect, TLS handshake, time to first byte) and response headers.
+Redirects are not followed, so timing always describes a single request`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, err := probe(args[0])
+		if err != nil {
+			return err
+		}
+
+		if probeOptions.json {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(report.results())
+		}
+		return report.write(cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	probeCmd.Flags().StringVarP(&probeOptions.method, "method", "X", http.MethodGet, "Request method")
+	probeCmd.Flags().StringArrayVarP(&probeOptions.headers, "header", "H", nil, "Request header in 'Name: Value' format, can be repeated")
+	probeCmd.Flags().StringVarP(&probeOptions.data, "data", "d", "", "Request body")
+	probeCmd.Flags().DurationVar(&probeOptions.timeout, "timeout", defaultProbeTimeout, "Request timeout")
+	probeCmd.Flags().BoolVarP(&probeOptions.insecure, "insecure", "k", false, "Skip server certificate verification")
+	probeCmd.Flags().BoolVar(&probeOptions.json, "json", false, "Print report as JSON")
+	rootCmd.AddCommand(probeCmd)
+}
+
+// probeReport describes probed response, phases not taken by request, e.g. DNS lookup for IP address, are left zero
+type probeReport struct {
+	method   string
+	url      string
+	response *http.Response
+	bodySize int64
+
+	dnsLookup    time.Duration
+	connect      time.Duration
+	tlsHandshake time.Duration
+	ttfb         time.Duration
+	total        time.Duration
+}
+
+func probe(target string) (*probeReport, error) {
+	var body io.Reader
+	if len(probeOptions.data) > 0 {
+		body = strings.NewReader(probeOptions.data)
+	}
+
+	req, err := http.NewRequest(strings.ToUpper(probeOptions.method), target, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, header := range probeOptions.headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || len(strings.TrimSpace(name)) == 0 {
+			return nil, fmt.Errorf("invalid header %q, expected 'Name: Value'", header)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Add(name, value)
+	}
+
+	report := &probeReport{method: req.Method, url: target}
+
+	var dnsStart, connectStart, tlsStart time.Time
+	start := time.Now()
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:           func(httptrace.DNSDoneInfo) { report.dnsLookup = time.Since(dnsStart) },
+		ConnectStart:      func(string, string) { connectStart = time.Now() },
+		ConnectDone:       func(string, string, error) { report.connect = time.Since(connectStart) },
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { report.tlsHandshake = time.Since(tlsStart) },
+		GotFirstResponseByte: func() {
+			report.ttfb = time.Since(start)
+		},
+	}))
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if probeOptions.insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   probeOptions.timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	report.response = res
+	if report.bodySize, err = io.Copy(io.Discard, res.Body); err != nil {
+		return nil, fmt.Errorf("unable to read response body: %w", err)
+	}
+	report.total = time.Since(start)
+
+	return report, nil
+}
+
+// results represents report in the same format echo handlers use
+func (p *probeReport) results() map[string]any {
+	results := map[string]any{
+		"method":      p.method,
+		"url":         p.url,
+		"status":      p.response.Status,
+		"status_code": p.response.StatusCode,
+		"proto":       p.response.Proto,
+		"headers":     handler.HeaderList(p.response.Header),
+		"body_size":   p.bodySize,
+		"timing": map[string]string{
+			"dns_lookup":    p.dnsLookup.String(),
+			"connect":       p.connect.String(),
+			"tls_handshake": p.tlsHandshake.String(),
+			"ttfb":          p.ttfb.String(),
+			"total":         p.total.String(),
+		},
+	}
+
+	if p.response.TLS != nil {
+		results["tls"] = handler.TLSInfo(p.response.TLS)
+	}
+
+	return results
+}
+
+func (p *probeReport) write(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n%s %s\n\n", p.method, p.url, p.response.Proto, p.response.Status)
+
+	fmt.Fprintln(&b, "Timing:")
+	for _, phase := range []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"DNS lookup", p.dnsLookup},
+		{"Connect", p.connect},
+		{"TLS handshake", p.tlsHandshake},
+		{"Time to first byte", p.ttfb},
+		{"Total", p.total},
+	} {
+		fmt.Fprintf(&b, "  %-20s %s\n", phase.name, phase.duration)
+	}
+
+	if p.response.TLS != nil {
+		fmt.Fprintln(&b, "\nTLS:")
+		info := handler.TLSInfo(p.response.TLS)
+		for _, key := range []string{"version", "cipher_suite", "server_name", "negotiated_protocol"} {
+			fmt.Fprintf(&b, "  %-20s %v\n", key, info[key])
+		}
+	}
+
+	fmt.Fprintln(&b, "\nHeaders:")
+	for _, header := range handler.HeaderList(p.response.Header) {
+		for _, value := range header.Values {
+			fmt.Fprintf(&b, "  %s: %s\n", header.Name, value)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nBody: %d bytes\n", p.bodySize)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
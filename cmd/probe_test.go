@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProbeCommand(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Probe", r.Header.Get("X-Probe")+" "+r.Method)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	var out strings.Builder
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		probeOptions.json = false
+		probeOptions.method = http.MethodGet
+		probeOptions.headers = nil
+	}()
+
+	rootCmd.SetArgs([]string{"probe", server.URL, "-X", "post", "-H", "X-Probe: test"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"202 Accepted", "Time to first byte", "X-Probe: test POST", "Body: 5 bytes"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in probe output:\n%s", expected, out.String())
+		}
+	}
+
+	out.Reset()
+	probeOptions.headers = nil
+	rootCmd.SetArgs([]string{"probe", server.URL, "--json"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	var result map[string]any
+	if err := json.Unmarshal([]byte(out.String()), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result["status_code"] != float64(http.StatusAccepted) || result["body_size"] != float64(5) {
+		t.Errorf("unexpected probe report: %v", result)
+	}
+	if _, ok := result["timing"].(map[string]any)["ttfb"]; !ok {
+		t.Errorf("expected ttfb timing in probe report: %v", result)
+	}
+}
+
+func TestProbeInvalidHeader(t *testing.T) {
+	probeOptions.headers = []string{"invalid"}
+	defer func() { probeOptions.headers = nil }()
+
+	if _, err := probe("http://localhost"); err == nil {
+		t.Error("expected invalid header error")
+	}
+}
@@ -20,14 +20,21 @@ import (
 	"fmt"
 	"github.com/rovergulf/busybox/handler"
 	"github.com/spf13/cobra"
+	"net/http"
+	"net/url"
 	"os"
-	"os/signal"
-	"syscall"
+	"path"
+	"strings"
+	"time"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 )
 
+const (
+	remoteConfigTimeout = 10 * time.Second
+)
+
 var cfgFile string
 
 // rootCmd represents the base command when called without any subcommands
@@ -41,15 +48,6 @@ as a incoming HTTP request debug tool`,
 		//defer cancel()
 
 		h := new(handler.Handler)
-
-		exitChan := make(chan os.Signal, 1)
-		signal.Notify(exitChan, os.Interrupt, os.Kill, syscall.SIGTERM)
-
-		go func() {
-			sig := <-exitChan
-			h.GracefulShutdown(sig.String())
-		}()
-
 		return h.Run()
 	},
 }
@@ -70,27 +68,178 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file path or http(s) URL")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().String("jaeger-trace", os.Getenv("JAEGER_TRACING_COLLECTOR"), "Jaeger tracing collector address")
-	rootCmd.Flags().String("env", "dev", "App environment")
+	rootCmd.Flags().String("trace-exporter", "", "Trace exporter: jaeger, otlp-grpc or otlp-http")
+	rootCmd.Flags().String("service-name", "", "Service name reported with spans and metrics, busybox-<env> if not set")
+	rootCmd.Flags().String("service-version", "", "Service version reported with spans and metrics, build version if not set")
+	rootCmd.Flags().StringToString("resource-attributes", nil, "Resource attributes reported with spans and metrics, e.g. deployment.environment=staging")
+	rootCmd.Flags().String("otlp-endpoint", "", "OTLP collector endpoint, OTEL_EXPORTER_OTLP_* env vars are used if not set")
+	rootCmd.Flags().String("trace-sampler", "parentbased_ratio", "Trace sampler: always, never, ratio or parentbased_ratio")
+	rootCmd.Flags().Bool("trace-required", false, "Fail to start if trace exporter can not be initialized")
+	rootCmd.Flags().Duration("trace-shutdown-timeout", 5*time.Second, "Time to wait for pending spans to be exported on shutdown")
+	rootCmd.Flags().Float64("trace-sample-ratio", -1, "Trace sample ratio used by ratio samplers, negative ratio is decided by env: 1, or 0.1 in production")
+	rootCmd.Flags().String("env", "dev", "App environment, main, prod and production are treated as production")
 	rootCmd.Flags().Bool("log-json", false, "Enable JSON logging")
+	rootCmd.Flags().String("log-level", "", "Log level: debug, info, warn or error. Defaults to info in production env, debug otherwise")
+	rootCmd.Flags().Bool("log-response-bodies", false, "Log /debug routes response bodies at debug level")
+	rootCmd.Flags().Int("log-response-body-max-bytes", 4096, "Maximum logged response body size")
 	rootCmd.Flags().Bool("log-stacktrace", false, "Enable logger stacktrace")
-	rootCmd.Flags().String("listen-addr", ":8081", "TCP address listen to")
+	rootCmd.Flags().StringToString("access-log-levels", nil, "Access log level by response status class, e.g. 2xx=debug,4xx=info. Info for 1xx-3xx, warn for 4xx and error for 5xx if not set")
+	rootCmd.Flags().StringSlice("listen-addr", []string{handler.DefaultListenAddr}, "TCP addresses listen to, or Unix socket paths prefixed with unix:, can be repeated or comma separated")
+	rootCmd.Flags().String("listen-network", "tcp", "TCP listeners network: tcp binds wildcard address to both IPv4 and IPv6, tcp4 or tcp6 to a single family")
 	rootCmd.Flags().Bool("enable-profiling", false, "Enable http/pprof handler support")
+	rootCmd.Flags().String("admin-listen-addr", "", "Separate address to serve metrics, http/pprof and readiness check on")
+	rootCmd.Flags().String("pprof-listen-addr", "", "Separate address to serve http/pprof on instead of main listener")
+	rootCmd.Flags().String("tls-cert", "", "TLS certificate file path")
+	rootCmd.Flags().String("tls-key", "", "TLS private key file path")
+	rootCmd.Flags().String("tls-min-version", "", "Minimum TLS version: 1.0, 1.1, 1.2 or 1.3")
+	rootCmd.Flags().String("tls-client-auth", "none", "TLS client certificate policy: none, request, verify_if_given or require")
+	rootCmd.Flags().String("tls-client-ca-file", "", "PEM file of CA certificates client certificates are verified against, system roots are used if not set")
+	rootCmd.Flags().Int("max-header-bytes", http.DefaultMaxHeaderBytes, "Maximum size of request headers in bytes")
+	rootCmd.Flags().Int("max-header-count", 100, "Maximum number of request header lines")
+	rootCmd.Flags().Int("max-echoed-headers", 0, "Maximum number of headers reported by echo, 0 means no limit")
+	rootCmd.Flags().Bool("strict-body", false, "Respond with 400 if request body can not be decoded instead of reporting the error")
+	rootCmd.Flags().Bool("allow-method-override", false, "Route POST requests by X-HTTP-Method-Override header method")
+	rootCmd.Flags().Bool("json-use-number", false, "Decode JSON body numbers as written instead of float64, so large integers keep precision")
+	rootCmd.Flags().Int64("max-body-bytes", 1<<20, "Maximum request body size to read and echo")
+	rootCmd.Flags().Int64("multipart-max-memory", 32<<20, "Maximum memory used to parse multipart forms, rest is stored on disk. Capped by max body bytes")
+	rootCmd.Flags().Duration("max-delay", 60*time.Second, "Maximum delay allowed for /delay endpoint")
+	rootCmd.Flags().StringSlice("cors-allowed-origins", nil, "CORS allowed origins, '*' allows any. Any origin is reflected if not set, or '*' is used in production")
+	rootCmd.Flags().StringSlice("cors-allowed-methods", nil, "CORS allowed methods")
+	rootCmd.Flags().StringSlice("cors-allowed-headers", nil, "CORS allowed headers")
+	rootCmd.Flags().String("cors-allow-credentials", "auto", "CORS allow credentials: true, false or auto, which allows them except production")
+	rootCmd.Flags().Lookup("cors-allow-credentials").NoOptDefVal = "true"
+	rootCmd.Flags().StringSlice("readiness-tcp-targets", nil, "TCP addresses dialed on every readiness check")
+	rootCmd.Flags().Duration("readiness-check-timeout", 2*time.Second, "Readiness check timeout")
+	rootCmd.Flags().StringSlice("metric-duration-buckets", nil, "Request duration histogram buckets in seconds, prometheus defaults are used if not set")
+	rootCmd.Flags().String("metrics-exporter", "prometheus", "Metrics exporter: prometheus, or otlp-grpc and otlp-http to push request metrics to otlp-endpoint as well")
+	rootCmd.Flags().Duration("metrics-export-interval", 60*time.Second, "Interval metrics are pushed to OTLP collector with")
+	rootCmd.Flags().String("json-indent", "  ", "Indent used for pretty JSON responses requested with ?pretty=true")
+	rootCmd.Flags().StringSlice("redact-headers", []string{"Authorization", "Cookie", "X-CSRF-Token"}, "Headers which values are masked in echo output")
+	rootCmd.Flags().StringSlice("redact-cookies", nil, "Cookie names which values are masked in echo output")
+	rootCmd.Flags().StringSlice("header-allowlist", nil, "Only these headers are included in echo output if set")
+	rootCmd.Flags().StringSlice("env-echo-allowlist", nil, "Environment variable name prefixes exposed by /debug/env, nothing is exposed if empty")
+	rootCmd.Flags().StringSlice("trusted-proxies", nil, "Proxy CIDRs or addresses allowed to set client IP with forwarding headers")
+	rootCmd.Flags().String("auth-username", "", "Basic auth username required for protected routes")
+	rootCmd.Flags().String("auth-password", "", "Basic auth password required for protected routes")
+	rootCmd.Flags().StringSlice("auth-bearer-token", nil, "Bearer tokens accepted for protected routes")
+	rootCmd.Flags().Float64("rate-limit-rps", 0, "Requests per second allowed per client IP, rate limiting is disabled if not set")
+	rootCmd.Flags().Int("rate-limit-burst", 0, "Requests burst allowed per client IP, defaults to rate-limit-rps")
+	rootCmd.Flags().Int("rate-limit-max-clients", 10000, "Maximum number of client IPs tracked by rate limiter")
+	rootCmd.Flags().Int("max-concurrent-requests", 0, "Requests served concurrently before responding with 503, unlimited if not set")
+	rootCmd.Flags().Bool("watch-config", false, "Apply runtime settings whenever config file is changed")
+	rootCmd.Flags().StringSlice("disabled-routes", nil, "Routes which are not mounted, e.g. /debug/pprof,/metrics")
+	rootCmd.Flags().String("base-path", "", "Path prefix all routes are mounted at, e.g. /tools/busybox")
+	rootCmd.Flags().Bool("enable-compression", false, "Compress responses with gzip or deflate if client accepts it")
+	rootCmd.Flags().Int("compression-min-bytes", 1024, "Minimal response size in bytes to be compressed")
+	rootCmd.Flags().StringToString("response-headers", nil, "Headers added to every response, e.g. X-Frame-Options=DENY")
+	rootCmd.Flags().Int64("max-bytes", 10<<20, "Maximum bytes count allowed for /bytes endpoint")
+	rootCmd.Flags().Int("max-redirects", 20, "Maximum redirects count allowed for /redirect endpoint")
+	rootCmd.Flags().Bool("enable-h2c", false, "Accept HTTP/2 cleartext connections alongside HTTP/1.1")
+	rootCmd.Flags().Int("request-history-size", 100, "Number of recently handled requests kept for /debug/requests")
+	rootCmd.Flags().Duration("read-header-timeout", 10*time.Second, "Time allowed to read request headers, negative value disables timeout")
+	rootCmd.Flags().Duration("read-timeout", 60*time.Second, "Time allowed to read whole request, negative value disables timeout")
+	rootCmd.Flags().Duration("write-timeout", 90*time.Second, "Time allowed to write response, must exceed max delay, negative value disables timeout")
+	rootCmd.Flags().Duration("idle-timeout", 120*time.Second, "Time to keep idle keep-alive connections, negative value disables timeout")
+	rootCmd.Flags().Duration("request-timeout", 0, "Time to handle request before responding with 503, disabled if not set")
+	rootCmd.Flags().Duration("shutdown-timeout", 15*time.Second, "Time to wait for in-flight requests on shutdown")
+	rootCmd.Flags().Bool("reload-on-sighup", false, "Reload config file on SIGHUP instead of shutting down")
+	rootCmd.Flags().Bool("reuse-port", false, "Bind TCP listener with SO_REUSEPORT, so it can be restarted on SIGHUP or taken over by new process without downtime")
 
 	viper.BindPFlag("log_json", rootCmd.Flags().Lookup("log-json"))
 	viper.BindPFlag("log_stacktrace", rootCmd.Flags().Lookup("log-stacktrace"))
-	viper.BindPFlag("jaeger_addr", rootCmd.Flags().Lookup("jaeger_addr"))
+	viper.BindPFlag("access_log_levels", rootCmd.Flags().Lookup("access-log-levels"))
+	viper.BindPFlag("log_level", rootCmd.Flags().Lookup("log-level"))
+	viper.BindPFlag("log_response_bodies", rootCmd.Flags().Lookup("log-response-bodies"))
+	viper.BindPFlag("log_response_body_max_bytes", rootCmd.Flags().Lookup("log-response-body-max-bytes"))
+	viper.BindPFlag("jaeger_trace", rootCmd.Flags().Lookup("jaeger-trace"))
+	viper.BindPFlag("trace_exporter", rootCmd.Flags().Lookup("trace-exporter"))
+	viper.BindPFlag("service_name", rootCmd.Flags().Lookup("service-name"))
+	viper.BindPFlag("service_version", rootCmd.Flags().Lookup("service-version"))
+	viper.BindPFlag("resource_attributes", rootCmd.Flags().Lookup("resource-attributes"))
+	viper.BindPFlag("otlp_endpoint", rootCmd.Flags().Lookup("otlp-endpoint"))
+	viper.BindPFlag("trace_sampler", rootCmd.Flags().Lookup("trace-sampler"))
+	viper.BindPFlag("trace_required", rootCmd.Flags().Lookup("trace-required"))
+	viper.BindPFlag("trace_shutdown_timeout", rootCmd.Flags().Lookup("trace-shutdown-timeout"))
+	viper.BindPFlag("trace_sample_ratio", rootCmd.Flags().Lookup("trace-sample-ratio"))
 	viper.BindPFlag("listen_addr", rootCmd.Flags().Lookup("listen-addr"))
+	viper.BindPFlag("listen_network", rootCmd.Flags().Lookup("listen-network"))
 	viper.BindPFlag("env", rootCmd.Flags().Lookup("env"))
 	viper.BindPFlag("enable_profiling", rootCmd.Flags().Lookup("enable-profiling"))
+	viper.BindPFlag("admin_listen_addr", rootCmd.Flags().Lookup("admin-listen-addr"))
+	viper.BindPFlag("pprof_listen_addr", rootCmd.Flags().Lookup("pprof-listen-addr"))
+	viper.BindPFlag("tls_cert", rootCmd.Flags().Lookup("tls-cert"))
+	viper.BindPFlag("tls_key", rootCmd.Flags().Lookup("tls-key"))
+	viper.BindPFlag("tls_min_version", rootCmd.Flags().Lookup("tls-min-version"))
+	viper.BindPFlag("tls_client_auth", rootCmd.Flags().Lookup("tls-client-auth"))
+	viper.BindPFlag("tls_client_ca_file", rootCmd.Flags().Lookup("tls-client-ca-file"))
+	viper.BindPFlag("max_header_bytes", rootCmd.Flags().Lookup("max-header-bytes"))
+	viper.BindPFlag("max_header_count", rootCmd.Flags().Lookup("max-header-count"))
+	viper.BindPFlag("max_echoed_headers", rootCmd.Flags().Lookup("max-echoed-headers"))
+	viper.BindPFlag("strict_body", rootCmd.Flags().Lookup("strict-body"))
+	viper.BindPFlag("allow_method_override", rootCmd.Flags().Lookup("allow-method-override"))
+	viper.BindPFlag("json_use_number", rootCmd.Flags().Lookup("json-use-number"))
+	viper.BindPFlag("max_body_bytes", rootCmd.Flags().Lookup("max-body-bytes"))
+	viper.BindPFlag("multipart_max_memory", rootCmd.Flags().Lookup("multipart-max-memory"))
+	viper.BindPFlag("max_delay", rootCmd.Flags().Lookup("max-delay"))
+	viper.BindPFlag("cors_allowed_origins", rootCmd.Flags().Lookup("cors-allowed-origins"))
+	viper.BindPFlag("cors_allowed_methods", rootCmd.Flags().Lookup("cors-allowed-methods"))
+	viper.BindPFlag("cors_allowed_headers", rootCmd.Flags().Lookup("cors-allowed-headers"))
+	viper.BindPFlag("cors_allow_credentials", rootCmd.Flags().Lookup("cors-allow-credentials"))
+	viper.BindPFlag("readiness_tcp_targets", rootCmd.Flags().Lookup("readiness-tcp-targets"))
+	viper.BindPFlag("readiness_check_timeout", rootCmd.Flags().Lookup("readiness-check-timeout"))
+	viper.BindPFlag("metric_duration_buckets", rootCmd.Flags().Lookup("metric-duration-buckets"))
+	viper.BindPFlag("metrics_exporter", rootCmd.Flags().Lookup("metrics-exporter"))
+	viper.BindPFlag("metrics_export_interval", rootCmd.Flags().Lookup("metrics-export-interval"))
+	viper.BindPFlag("json_indent", rootCmd.Flags().Lookup("json-indent"))
+	viper.BindPFlag("redact_headers", rootCmd.Flags().Lookup("redact-headers"))
+	viper.BindPFlag("redact_cookies", rootCmd.Flags().Lookup("redact-cookies"))
+	viper.BindPFlag("header_allowlist", rootCmd.Flags().Lookup("header-allowlist"))
+	viper.BindPFlag("env_echo_allowlist", rootCmd.Flags().Lookup("env-echo-allowlist"))
+	viper.BindPFlag("trusted_proxies", rootCmd.Flags().Lookup("trusted-proxies"))
+	viper.BindPFlag("auth_username", rootCmd.Flags().Lookup("auth-username"))
+	viper.BindPFlag("auth_password", rootCmd.Flags().Lookup("auth-password"))
+	viper.BindPFlag("auth_bearer_token", rootCmd.Flags().Lookup("auth-bearer-token"))
+	viper.BindPFlag("rate_limit_rps", rootCmd.Flags().Lookup("rate-limit-rps"))
+	viper.BindPFlag("rate_limit_burst", rootCmd.Flags().Lookup("rate-limit-burst"))
+	viper.BindPFlag("rate_limit_max_clients", rootCmd.Flags().Lookup("rate-limit-max-clients"))
+	viper.BindPFlag("max_concurrent_requests", rootCmd.Flags().Lookup("max-concurrent-requests"))
+	viper.BindPFlag("watch_config", rootCmd.Flags().Lookup("watch-config"))
+	viper.BindPFlag("disabled_routes", rootCmd.Flags().Lookup("disabled-routes"))
+	viper.BindPFlag("base_path", rootCmd.Flags().Lookup("base-path"))
+	viper.BindPFlag("enable_compression", rootCmd.Flags().Lookup("enable-compression"))
+	viper.BindPFlag("compression_min_bytes", rootCmd.Flags().Lookup("compression-min-bytes"))
+	viper.BindPFlag("response_headers", rootCmd.Flags().Lookup("response-headers"))
+	viper.BindPFlag("max_bytes", rootCmd.Flags().Lookup("max-bytes"))
+	viper.BindPFlag("max_redirects", rootCmd.Flags().Lookup("max-redirects"))
+	viper.BindPFlag("enable_h2c", rootCmd.Flags().Lookup("enable-h2c"))
+	viper.BindPFlag("request_history_size", rootCmd.Flags().Lookup("request-history-size"))
+	viper.BindPFlag("read_header_timeout", rootCmd.Flags().Lookup("read-header-timeout"))
+	viper.BindPFlag("read_timeout", rootCmd.Flags().Lookup("read-timeout"))
+	viper.BindPFlag("write_timeout", rootCmd.Flags().Lookup("write-timeout"))
+	viper.BindPFlag("idle_timeout", rootCmd.Flags().Lookup("idle-timeout"))
+	viper.BindPFlag("request_timeout", rootCmd.Flags().Lookup("request-timeout"))
+	viper.BindPFlag("shutdown_timeout", rootCmd.Flags().Lookup("shutdown-timeout"))
+	viper.BindPFlag("reload_on_sighup", rootCmd.Flags().Lookup("reload-on-sighup"))
+	viper.BindPFlag("reuse_port", rootCmd.Flags().Lookup("reuse-port"))
 }
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
+	viper.AutomaticEnv() // read in environment variables that match
+
+	if isRemoteConfig(cfgFile) {
+		if err := readRemoteConfig(cfgFile); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if cfgFile != "" {
 		// Use config file from the flag.
 		viper.SetConfigFile(cfgFile)
@@ -107,10 +256,46 @@ func initConfig() {
 		viper.SetConfigName(".busybox")
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
-
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
 		fmt.Println("Using config file:", viper.ConfigFileUsed())
 	}
 }
+
+// isRemoteConfig tells whether config flag is http(s) URL
+func isRemoteConfig(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// readRemoteConfig fetches config from URL, its type is detected by URL path extension and defaults to yaml.
+// Remote config is read once on start, it is not reloaded
+func readRemoteConfig(location string) error {
+	configURL, err := url.Parse(location)
+	if err != nil {
+		return fmt.Errorf("invalid config URL: %s", err)
+	}
+
+	client := &http.Client{Timeout: remoteConfigTimeout}
+	res, err := client.Get(configURL.String())
+	if err != nil {
+		return fmt.Errorf("unable to fetch config from %s: %s", configURL.Redacted(), err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to fetch config from %s: unexpected status %s", configURL.Redacted(), res.Status)
+	}
+
+	configType := strings.TrimPrefix(path.Ext(configURL.Path), ".")
+	if len(configType) == 0 {
+		configType = "yaml"
+	}
+	viper.SetConfigType(configType)
+
+	if err := viper.ReadConfig(res.Body); err != nil {
+		return fmt.Errorf("unable to parse config from %s: %s", configURL.Redacted(), err)
+	}
+
+	fmt.Println("Using remote config:", configURL.Redacted())
+	return nil
+}
@@ -0,0 +1,86 @@
+//go:build unix
+
+package cmd
+
+import (
+	"github.com/rovergulf/busybox/handler"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// freeListenAddr returns loopback address with a port not used by anything else
+func freeListenAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to find free port: %s", err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+// resetListenAddrFlag restores --listen-addr default, viper reads listen_addr from it as the flag is bound.
+// Slice flag appends to its values once it has been set, so value is replaced with a fresh one
+func resetListenAddrFlag() {
+	flag := rootCmd.Flags().Lookup("listen-addr")
+	fresh := pflag.NewFlagSet(rootCmd.Name(), pflag.ContinueOnError)
+	fresh.StringSlice(flag.Name, []string{handler.DefaultListenAddr}, flag.Usage)
+	flag.Value = fresh.Lookup(flag.Name).Value
+	flag.Changed = false
+}
+
+func TestListenAddrFlag(t *testing.T) {
+	addr := freeListenAddr(t)
+	rootCmd.SetArgs([]string{"--listen-addr", addr})
+	t.Cleanup(func() {
+		rootCmd.SetArgs(nil)
+		resetListenAddrFlag()
+	})
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- rootCmd.Execute()
+	}()
+
+	var started bool
+	for i := 0; i < 50 && !started; i++ {
+		select {
+		case err := <-errChan:
+			t.Fatalf("Command stopped before serving: %v", err)
+		default:
+		}
+
+		if res, err := http.Get("http://" + addr + "/health"); err == nil {
+			res.Body.Close()
+			started = true
+		} else {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	// server traps SIGTERM and shuts down gracefully, it is stopped before test returns even if it has not responded
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("Unable to send shutdown signal: %s", err)
+	}
+
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Errorf("Command failed: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Server is not stopped on SIGTERM")
+	}
+
+	if !started {
+		t.Errorf("Server is not listening on --listen-addr port")
+	}
+	if addrs := viper.GetStringSlice("listen_addr"); len(addrs) != 1 || addrs[0] != addr {
+		t.Errorf("Expected listen_addr to be read from flag, got: %v", addrs)
+	}
+}
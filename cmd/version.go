@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/rovergulf/busybox/handler"
+	"github.com/spf13/cobra"
+	"runtime"
+)
+
+var versionJSON bool
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print busybox version, git commit, build date and Go version",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if versionJSON {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			return encoder.Encode(map[string]string{
+				"version":    handler.AppVersion,
+				"git_commit": handler.GitCommit,
+				"build_date": handler.BuildDate,
+				"go_version": runtime.Version(),
+			})
+		}
+
+		_, err := fmt.Fprintf(cmd.OutOrStdout(), "busybox %s (commit %s, built %s, %s)\n",
+			versionValue(handler.AppVersion), versionValue(handler.GitCommit), versionValue(handler.BuildDate), runtime.Version())
+		return err
+	},
+}
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "Print version details as JSON")
+	rootCmd.AddCommand(versionCmd)
+}
+
+// versionValue substitutes build details not set with ldflags
+func versionValue(value string) string {
+	if len(value) == 0 {
+		return "unknown"
+	}
+	return value
+}
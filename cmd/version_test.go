@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/rovergulf/busybox/handler"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestVersionCommand(t *testing.T) {
+	handler.AppVersion, handler.GitCommit = "v1.2.3", "abc123"
+	defer func() {
+		handler.AppVersion, handler.GitCommit = "", ""
+		versionJSON = false
+	}()
+
+	var out strings.Builder
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	rootCmd.SetArgs([]string{"version"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "busybox v1.2.3 (commit abc123, built unknown, " + runtime.Version() + ")\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+
+	out.Reset()
+	rootCmd.SetArgs([]string{"version", "--json"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	var result map[string]string
+	if err := json.Unmarshal([]byte(out.String()), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result["version"] != "v1.2.3" || result["git_commit"] != "abc123" || result["go_version"] != runtime.Version() {
+		t.Errorf("unexpected version output: %v", result)
+	}
+}
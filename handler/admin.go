@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"github.com/spf13/viper"
+	"net/http"
+	"time"
+)
+
+// adminEnabled tells whether metrics, profiler and readiness check are served by admin_listen_addr listener
+func adminEnabled() bool {
+	return len(viper.GetString("admin_listen_addr")) > 0
+}
+
+// profilePaths are net/http/pprof endpoints writing response for as long as ?seconds requests
+var profilePaths = []string{"/debug/pprof/profile", "/debug/pprof/trace"}
+
+// serveAux starts auxiliary listener at addr serving router, it is shut down along with main server.
+// Server has the same timeouts and header limit as main servers, except write_timeout is applied per request,
+// so it is left off for profilePaths which would be cut by it otherwise
+func (h *Handler) serveAux(name, addr string, router http.Handler) error {
+	ln, err := listen(addr)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Addr: addr,
+		Handler: h.writeTimeout(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.serve(router, w, r)
+		})),
+		MaxHeaderBytes: maxHeaderBytes(),
+	}
+	setServerTimeouts(server)
+	server.WriteTimeout = 0
+	h.auxServers = append(h.auxServers, server)
+
+	h.logger.Infow("Starting "+name+" HTTP Server", "listen_addr", addr)
+	go func() {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			h.logger.Errorw(name+" HTTP Server failed", "err", err)
+		}
+	}()
+
+	return nil
+}
+
+// writeTimeout sets write_timeout deadline to connection of every request but profilePaths ones
+func (h *Handler) writeTimeout(next http.Handler) http.Handler {
+	timeout := serverTimeout("write_timeout", defaultWriteTimeout)
+	if timeout <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !containsFold(profilePaths, r.URL.Path) {
+			if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+				h.logger.Warnw("Unable to set write deadline", "path", r.URL.Path, "err", err)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveAdmin starts admin listener at admin_listen_addr, if it is set
+func (h *Handler) serveAdmin() error {
+	if !adminEnabled() {
+		return nil
+	}
+	return h.serveAux("admin", viper.GetString("admin_listen_addr"), h.newAdminRouter())
+}
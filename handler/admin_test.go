@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"bytes"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWriteTimeout(t *testing.T) {
+	viper.Set("write_timeout", 100*time.Millisecond)
+	defer viper.Set("write_timeout", nil)
+
+	h := &Handler{logger: zap.NewNop().Sugar()}
+	server := httptest.NewServer(h.writeTimeout(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write(bytes.Repeat([]byte("x"), 1<<20))
+	})))
+	defer server.Close()
+
+	cases := []struct {
+		path string
+		cut  bool
+	}{
+		{path: "/debug/pprof/heap", cut: true},
+		{path: "/debug/pprof/profile"},
+		{path: "/debug/pprof/trace"},
+	}
+
+	for _, c := range cases {
+		res, err := http.Get(server.URL + c.path)
+		if err == nil {
+			_, err = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		if cut := err != nil; cut != c.cut {
+			t.Errorf("Expected %s response to be cut by write_timeout: %t, got error: %v", c.path, c.cut, err)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+// anythingHandler responds with request echo for any method and path under /anything,
+// path after /anything/ is reported as path_suffix, so it does not depend on base_path routes are mounted at
+func (h *Handler) anythingHandler(w http.ResponseWriter, r *http.Request) {
+	results, ok := h.echoResults(w, r)
+	if !ok {
+		return
+	}
+	suffix := chi.URLParam(r, "*")
+	results.PathSuffix = &suffix
+	writeResponse(w, r, results)
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/viper"
+	"net/http"
+	"strings"
+)
+
+const (
+	bearerPrefix = "Bearer "
+)
+
+func secureCompare(given, expected string) bool {
+	return subtle.ConstantTimeCompare([]byte(given), []byte(expected)) == 1
+}
+
+// bearerTokens returns auth_bearer_token values, comma-separated list is allowed
+func bearerTokens() []string {
+	var tokens []string
+	for _, value := range viper.GetStringSlice("auth_bearer_token") {
+		for _, token := range strings.Split(value, ",") {
+			if token = strings.TrimSpace(token); len(token) > 0 {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+	return tokens
+}
+
+// bearerToken extracts token from Authorization header
+func bearerToken(r *http.Request) (string, bool) {
+	authorization := r.Header.Get("Authorization")
+	if len(authorization) < len(bearerPrefix) || !strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+		return "", false
+	}
+
+	token := strings.TrimSpace(authorization[len(bearerPrefix):])
+	return token, len(token) > 0
+}
+
+func validBasicAuth(r *http.Request, username, password string) bool {
+	givenUsername, givenPassword, ok := r.BasicAuth()
+	// both values are always compared to keep timing independent of which one is wrong
+	usernameMatch := secureCompare(givenUsername, username)
+	passwordMatch := secureCompare(givenPassword, password)
+	return ok && usernameMatch && passwordMatch
+}
+
+func validBearerToken(r *http.Request, tokens []string) bool {
+	given, ok := bearerToken(r)
+	if !ok {
+		return false
+	}
+
+	// every token is compared to keep timing independent of which one matches
+	valid := false
+	for _, token := range tokens {
+		if secureCompare(given, token) {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// authenticate requires either HTTP Basic Auth credentials matching auth_username and auth_password,
+// or one of auth_bearer_token tokens in Authorization header.
+// Requests are passed through as is if no credentials are configured
+func (h *Handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := requestSettings(r)
+		username, password := cfg.authUsername, cfg.authPassword
+		basicEnabled := len(username) > 0 || len(password) > 0
+
+		tokens := cfg.bearerTokens
+		bearerEnabled := len(tokens) > 0
+
+		if !basicEnabled && !bearerEnabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if (basicEnabled && validBasicAuth(r, username, password)) || (bearerEnabled && validBearerToken(r, tokens)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if basicEnabled {
+			w.Header().Add("WWW-Authenticate", `Basic realm="busybox", charset="UTF-8"`)
+		}
+		if bearerEnabled {
+			w.Header().Add("WWW-Authenticate", `Bearer realm="busybox"`)
+		}
+		writeError(w, r, http.StatusUnauthorized, "unauthorized")
+	})
+}
+
+// basicAuthHandler responds with authenticated user if request Basic Auth credentials match
+// {user} and {passwd} path params. It is a fixture for client auth code, unrelated to authenticate middleware
+func (h *Handler) basicAuthHandler(w http.ResponseWriter, r *http.Request) {
+	user := chi.URLParam(r, "user")
+	if !validBasicAuth(r, user, chi.URLParam(r, "passwd")) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="busybox", charset="UTF-8"`)
+		writeError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	writeResponse(w, r, map[string]any{
+		"authenticated": true,
+		"user":          user,
+	})
+}
+
+// bearerHandler responds with given token if request carries any non-empty bearer token, 401 otherwise.
+// It is a fixture for client token handling, tokens are not validated against auth_bearer_token
+func (h *Handler) bearerHandler(w http.ResponseWriter, r *http.Request) {
+	token, ok := bearerToken(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="busybox"`)
+		writeError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	writeResponse(w, r, map[string]any{
+		"authenticated": true,
+		"token":         token,
+	})
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"encoding/base64"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// decodeBase64 decodes standard or URL-safe base64 value, padding is optional
+func decodeBase64(value string) ([]byte, error) {
+	value = strings.TrimRight(value, "=")
+
+	encoding := base64.RawStdEncoding
+	if strings.ContainsAny(value, "-_") {
+		encoding = base64.RawURLEncoding
+	}
+	return encoding.DecodeString(value)
+}
+
+// base64Handler responds with base64 decoded path value as plain text, same as httpbin does.
+// Standard alphabet values containing '/' must be sent with it escaped as %2F
+func (h *Handler) base64Handler(w http.ResponseWriter, r *http.Request) {
+	value := chi.URLParam(r, "value")
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		value = unescaped
+	}
+
+	data, err := decodeBase64(value)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid base64 value")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(data); err != nil {
+		h.requestLogger(r).Debugw("Unable to write decoded value", "err", err)
+	}
+}
@@ -0,0 +1,317 @@
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultMaxBodyBytes       = 1 << 20  // 1MiB
+	defaultMultipartMaxMemory = 32 << 20 // 32MiB, same as net/http uses
+)
+
+func maxBodyBytes() int64 {
+	if limit := viper.GetInt64("max_body_bytes"); limit > 0 {
+		return limit
+	}
+	return defaultMaxBodyBytes
+}
+
+// multipartMaxMemory is capped by max_body_bytes, as body larger than it is never read
+func multipartMaxMemory() int64 {
+	limit := viper.GetInt64("multipart_max_memory")
+	if limit <= 0 {
+		limit = defaultMultipartMaxMemory
+	}
+	if bodyLimit := maxBodyBytes(); limit > bodyLimit {
+		return bodyLimit
+	}
+	return limit
+}
+
+// strictBody tells whether body decoding failure is responded with an error,
+// it is enabled with strict_body config or ?strict query param
+func strictBody(r *http.Request) bool {
+	if requestSettings(r).strictBody {
+		return true
+	}
+
+	values, ok := r.URL.Query()["strict"]
+	if !ok {
+		return false
+	}
+
+	// bare ?strict enables it as well
+	if len(values[0]) == 0 {
+		return true
+	}
+
+	strict, _ := strconv.ParseBool(values[0])
+	return strict
+}
+
+// requestMediaType returns lower-cased media type of request Content-Type header without parameters
+func requestMediaType(r *http.Request) string {
+	contentType := r.Header.Get("Content-Type")
+	if len(contentType) == 0 {
+		return ""
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+
+	return mediaType
+}
+
+func isJSONMediaType(mediaType string) bool {
+	// requests without Content-Type are treated as JSON, as it was the only supported format before
+	return mediaType == "" || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func isYAMLMediaType(mediaType string) bool {
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	}
+	return strings.HasSuffix(mediaType, "+yaml")
+}
+
+// yamlToJSONCompatible converts mappings with non-string keys decoded by yaml into string keyed maps,
+// so decoded body can be encoded as JSON
+func yamlToJSONCompatible(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for key, item := range value {
+			value[key] = yamlToJSONCompatible(item)
+		}
+		return value
+	case map[any]any:
+		converted := make(map[string]any, len(value))
+		for key, item := range value {
+			converted[fmt.Sprint(key)] = yamlToJSONCompatible(item)
+		}
+		return converted
+	case []any:
+		for i, item := range value {
+			value[i] = yamlToJSONCompatible(item)
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+// bodyLimitReader remembers whether http.MaxBytesReader limit is exceeded,
+// as decoders do not always preserve read errors
+type bodyLimitReader struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (b *bodyLimitReader) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+// writeBodyTooLarge responds with 413 error envelope including max_body_bytes limit
+func writeBodyTooLarge(w http.ResponseWriter, r *http.Request, limit int64) {
+	writeError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds max_body_bytes limit of %d bytes", limit), map[string]any{
+		"limit": limit,
+	})
+}
+
+// jsonErrorContext is a number of bytes reported before and after JSON decoding error offset
+const jsonErrorContext = 20
+
+// jsonDecodingError adds byte offset and input around it to JSON syntax and type errors,
+// so malformed field can be found in large payloads
+func jsonDecodingError(err error, data []byte) error {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return err
+	}
+
+	start, end := offset-jsonErrorContext, offset+jsonErrorContext
+	if start < 0 {
+		start = 0
+	}
+	if end > int64(len(data)) {
+		end = int64(len(data))
+	}
+	if start > end {
+		start = end
+	}
+
+	return fmt.Errorf("%w at offset %d near %q", err, offset, data[start:end])
+}
+
+// decompressBody replaces request body with decompressing reader according to Content-Encoding header
+// and returns detected encoding
+func decompressBody(r *http.Request) (string, error) {
+	encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+	switch encoding {
+	case "", "identity":
+		return "", nil
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return encoding, err
+		}
+		r.Body = reader
+	case "deflate":
+		reader, err := zlib.NewReader(r.Body)
+		if err != nil {
+			return encoding, err
+		}
+		r.Body = reader
+	default:
+		return encoding, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+
+	return encoding, nil
+}
+
+// decodeBody reads request body up to max_body_bytes and puts it into results
+// according to request Content-Type:
+// JSON and YAML are decoded into "body", text/* is copied as string into "body",
+// forms are parsed into "form" and "files", anything else is base64 encoded into "body_base64".
+// Compressed bodies are decompressed first
+func (h *Handler) decodeBody(w http.ResponseWriter, r *http.Request, results *EchoResponse) {
+	encoding, err := decompressBody(r)
+	if len(encoding) > 0 {
+		results.ContentEncoding = encoding
+	}
+	if err != nil {
+		h.requestLogger(r).Errorw("Unable to decompress body data", "encoding", encoding, "err", err)
+		results.BodyDecodingError = err.Error()
+		return
+	}
+
+	cfg := requestSettings(r)
+	// limit is applied to decompressed stream, so small compressed payload can't expand without bound
+	body := &bodyLimitReader{ReadCloser: http.MaxBytesReader(w, r.Body, cfg.maxBodyBytes)}
+	defer func() {
+		results.bodyTooLarge = body.exceeded
+	}()
+	mediaType := requestMediaType(r)
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		r.Body = body
+		h.decodeForm(r, results)
+		return
+	case "multipart/form-data":
+		r.Body = body
+		h.decodeMultipartForm(r, results, cfg.multipartMaxMemory)
+		return
+	}
+
+	if isJSONMediaType(mediaType) {
+		// raw input is kept to report it along with decoding error
+		var raw bytes.Buffer
+		var bodyData map[string]any
+		decoder := json.NewDecoder(io.TeeReader(body, &raw))
+		if cfg.jsonUseNumber {
+			// keeps numbers as written, so large integers are echoed without float64 precision loss
+			decoder.UseNumber()
+		}
+		if err := decoder.Decode(&bodyData); err != nil {
+			// decoder stops at the error, the rest of the body is reported as well
+			io.Copy(&raw, body)
+			err = jsonDecodingError(err, raw.Bytes())
+			h.requestLogger(r).Errorw("Unable to decode body data", "err", err)
+			results.BodyDecodingError = err.Error()
+			results.BodyRaw = raw.String()
+		} else {
+			results.Body = bodyData
+		}
+		return
+	}
+
+	if isYAMLMediaType(mediaType) {
+		var bodyData map[string]any
+		decoder := yaml.NewDecoder(body)
+		if err := decoder.Decode(&bodyData); err != nil {
+			h.requestLogger(r).Errorw("Unable to decode YAML body data", "err", err)
+			results.BodyDecodingError = err.Error()
+		} else {
+			results.Body = yamlToJSONCompatible(bodyData)
+		}
+		return
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		h.requestLogger(r).Errorw("Unable to read body data", "err", err)
+		results.BodyDecodingError = err.Error()
+		return
+	}
+
+	if strings.HasPrefix(mediaType, "text/") {
+		results.Body = string(data)
+		return
+	}
+
+	results.BodyBase64 = base64.StdEncoding.EncodeToString(data)
+	results.BodyEncoding = "base64"
+}
+
+func (h *Handler) decodeForm(r *http.Request, results *EchoResponse) {
+	if err := r.ParseForm(); err != nil {
+		h.requestLogger(r).Errorw("Unable to parse form data", "err", err)
+		results.BodyDecodingError = err.Error()
+		return
+	}
+
+	results.Form = map[string][]string(r.PostForm)
+}
+
+// decodeMultipartForm parses multipart form fields and reports uploaded files metadata only,
+// files exceeding multipart_max_memory are kept on disk by net/http and removed afterwards
+func (h *Handler) decodeMultipartForm(r *http.Request, results *EchoResponse, maxMemory int64) {
+	if err := r.ParseMultipartForm(maxMemory); err != nil {
+		h.requestLogger(r).Errorw("Unable to parse multipart form data", "err", err)
+		results.BodyDecodingError = err.Error()
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	results.Form = r.MultipartForm.Value
+
+	files := make(map[string][]EchoFile)
+	for name, headers := range r.MultipartForm.File {
+		for _, fh := range headers {
+			files[name] = append(files[name], EchoFile{
+				Filename:    fh.Filename,
+				Size:        fh.Size,
+				ContentType: fh.Header.Get("Content-Type"),
+			})
+		}
+	}
+	results.Files = files
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestJSONDecodingError(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "syntax error",
+			input:    `{"event": "push", "payload": {"ref": "main",, "size": 1}}`,
+			expected: `at offset 45 near "d\": {\"ref\": \"main\",, \"size\": 1}}"`,
+		},
+		{
+			name:     "type error",
+			input:    `["not", "an", "object"]`,
+			expected: `at offset 1 near "[\"not\", \"an\", \"object"`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var body map[string]any
+			err := json.NewDecoder(strings.NewReader(c.input)).Decode(&body)
+			if err == nil {
+				t.Fatal("Expected decoding error")
+			}
+
+			wrapped := jsonDecodingError(err, []byte(c.input))
+			if !strings.HasSuffix(wrapped.Error(), c.expected) {
+				t.Errorf("Expected error to end with '%s', got: '%s'", c.expected, wrapped)
+			}
+			if !errors.Is(wrapped, err) {
+				t.Errorf("Original error must be wrapped")
+			}
+		})
+	}
+
+	err := errors.New("unexpected EOF")
+	if jsonDecodingError(err, nil) != err {
+		t.Errorf("Errors without offset must be returned as is")
+	}
+}
+
+func TestMultipartMaxMemory(t *testing.T) {
+	defer viper.Set("multipart_max_memory", nil)
+	defer viper.Set("max_body_bytes", nil)
+
+	viper.Set("max_body_bytes", 1<<20)
+	if limit := multipartMaxMemory(); limit != 1<<20 {
+		t.Errorf("Expected multipart memory to be capped by max_body_bytes, got: %d", limit)
+	}
+
+	viper.Set("multipart_max_memory", 1024)
+	if limit := multipartMaxMemory(); limit != 1024 {
+		t.Errorf("Expected multipart_max_memory below max_body_bytes to be used, got: %d", limit)
+	}
+}
+
+func TestDecodeMultipartFormSpillsToDisk(t *testing.T) {
+	// multipart files exceeding memory limit are stored in os.TempDir
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreateFormFile("upload", "report.bin")
+	if err != nil {
+		t.Fatalf("Unable to create form file: %s", err)
+	}
+	part.Write(bytes.Repeat([]byte("x"), 64<<10))
+	writer.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/debug", &buf)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+
+	h := &Handler{logger: zap.NewNop().Sugar()}
+	var results EchoResponse
+	h.decodeMultipartForm(r, &results, 1024)
+
+	if files := results.Files["upload"]; len(files) != 1 || files[0].Size != 64<<10 {
+		t.Fatalf("Expected uploaded file to be reported, got: %v", results.Files)
+	}
+
+	// file kept in memory could still be opened, spilled one is removed along with its temp file
+	if f, err := r.MultipartForm.File["upload"][0].Open(); err == nil {
+		f.Close()
+		t.Errorf("Expected file larger than memory limit to be stored on disk and removed")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Unable to read temp dir: %s", err)
+	}
+	if len(entries) > 0 {
+		t.Errorf("Expected multipart temp files to be removed, got: %d", len(entries))
+	}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/viper"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMaxBytes = 10 << 20 // 10MiB
+)
+
+func maxBytes() int64 {
+	if limit := viper.GetInt64("max_bytes"); limit > 0 {
+		return limit
+	}
+	return defaultMaxBytes
+}
+
+// bytesHandler responds with n random bytes, capped by max_bytes.
+// Random data is reproducible if ?seed= is passed
+func (h *Handler) bytesHandler(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.ParseInt(chi.URLParam(r, "n"), 10, 64)
+	if err != nil || n < 0 {
+		writeError(w, r, http.StatusBadRequest, "invalid number of bytes")
+		return
+	}
+
+	if limit := requestSettings(r).maxBytes; n > limit {
+		n = limit
+	}
+
+	seed := time.Now().UnixNano()
+	if value := r.URL.Query().Get("seed"); len(value) > 0 {
+		if seed, err = strconv.ParseInt(value, 10, 64); err != nil {
+			writeError(w, r, http.StatusBadRequest, "invalid seed")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(n, 10))
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.CopyN(w, rand.New(rand.NewSource(seed)), n); err != nil {
+		h.requestLogger(r).Debugw("Unable to write random bytes", "err", err)
+	}
+}
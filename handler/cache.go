@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheValidators returns Last-Modified and ETag values reported by /cache,
+// both stay the same for server lifetime, so clients can revalidate cached responses
+func cacheValidators() (time.Time, string) {
+	return runDate.UTC().Truncate(time.Second), fmt.Sprintf(`"%x"`, runDate.UnixNano())
+}
+
+// etagMatches tells whether If-None-Match header value matches etag using weak comparison
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, value := range strings.Split(ifNoneMatch, ",") {
+		value = strings.TrimSpace(value)
+		if value == "*" || strings.TrimPrefix(value, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified evaluates conditional request headers, If-Modified-Since is ignored
+// if If-None-Match is present as RFC 9110 requires
+func notModified(r *http.Request, lastModified time.Time, etag string) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); len(ifNoneMatch) > 0 {
+		return etagMatches(ifNoneMatch, etag)
+	}
+
+	if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil {
+		return !lastModified.After(since)
+	}
+
+	return false
+}
+
+// cacheHandler responds with 304 if conditional request headers match Last-Modified or ETag it sets,
+// or with request echo otherwise. /cache/{seconds} sets Cache-Control max-age as well
+func (h *Handler) cacheHandler(w http.ResponseWriter, r *http.Request) {
+	if param := chi.URLParam(r, "seconds"); len(param) > 0 {
+		seconds, err := strconv.Atoi(param)
+		if err != nil || seconds < 0 {
+			writeError(w, r, http.StatusBadRequest, "invalid max-age seconds")
+			return
+		}
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", seconds))
+	}
+
+	lastModified, etag := cacheValidators()
+	w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	w.Header().Set("ETag", etag)
+
+	if notModified(r, lastModified, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if results, ok := h.echoResults(w, r); ok {
+		writeResponse(w, r, results)
+	}
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// parseTrustedProxies parses trusted_proxies list of CIDRs or single IP addresses, invalid entries are skipped
+func parseTrustedProxies(values []string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			if ip := net.ParseIP(value); ip != nil {
+				bits := 8 * len(ip.To16())
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 32
+				}
+				networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			}
+			continue
+		}
+
+		if _, network, err := net.ParseCIDR(value); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
+func isTrusted(ip net.IP, networks []*net.IPNet) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func remoteHost(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+// resolveClientIP returns request client IP address.
+// Forwarding headers are taken into account only if direct peer is one of trusted_proxies,
+// so spoofed headers from untrusted peers are ignored
+func resolveClientIP(r *http.Request, networks []*net.IPNet) string {
+	peer := remoteHost(r.RemoteAddr)
+	peerIP := net.ParseIP(peer)
+
+	if peerIP == nil || !isTrusted(peerIP, networks) {
+		return peer
+	}
+
+	// walk X-Forwarded-For from the right, the first untrusted address is the client
+	if forwardedFor := r.Header.Values("X-Forwarded-For"); len(forwardedFor) > 0 {
+		addrs := strings.Split(strings.Join(forwardedFor, ","), ",")
+		var clientIP string
+		for i := len(addrs) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(addrs[i]))
+			if ip == nil {
+				break
+			}
+
+			clientIP = ip.String()
+			if !isTrusted(ip, networks) {
+				break
+			}
+		}
+
+		if len(clientIP) > 0 {
+			return clientIP
+		}
+	}
+
+	for _, header := range []string{"CF-Connecting-IP", "X-Real-IP"} {
+		if ip := net.ParseIP(strings.TrimSpace(r.Header.Get(header))); ip != nil {
+			return ip.String()
+		}
+	}
+
+	return peer
+}
+
+// ipHandler responds with resolved client IP address only
+func (h *Handler) ipHandler(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, map[string]any{
+		"origin": ClientIPFromContext(r.Context()),
+	})
+}
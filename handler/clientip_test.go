@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolveClientIP(t *testing.T) {
+	networks := parseTrustedProxies([]string{"10.0.0.0/8"})
+
+	cases := []struct {
+		name       string
+		remoteAddr string
+		header     http.Header
+		expected   string
+	}{
+		{
+			name:       "untrusted peer headers are ignored",
+			remoteAddr: "203.0.113.10:1234",
+			header:     http.Header{"X-Forwarded-For": {"198.51.100.1"}},
+			expected:   "203.0.113.10",
+		},
+		{
+			name:       "trusted peer forwarded for",
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"X-Forwarded-For": {"198.51.100.1, 10.0.0.2"}},
+			expected:   "198.51.100.1",
+		},
+		{
+			name:       "trusted peer spoofed leftmost address",
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"X-Forwarded-For": {"1.1.1.1, 198.51.100.1"}},
+			expected:   "198.51.100.1",
+		},
+		{
+			name:       "trusted peer real ip",
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"X-Real-Ip": {"198.51.100.2"}},
+			expected:   "198.51.100.2",
+		},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/debug", nil)
+		r.RemoteAddr = c.remoteAddr
+		r.Header = c.header
+
+		if ip := resolveClientIP(r, networks); ip != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.name, c.expected, ip)
+		}
+	}
+}
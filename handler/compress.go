@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"github.com/spf13/viper"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultCompressionMinBytes = 1024
+)
+
+// compressionEncodings lists supported content codings in order of preference
+var compressionEncodings = []string{"gzip", "deflate"}
+
+func compressionMinBytes() int {
+	if size := viper.GetInt("compression_min_bytes"); size > 0 {
+		return size
+	}
+	return defaultCompressionMinBytes
+}
+
+// acceptedEncoding picks supported content coding from request Accept-Encoding header
+// preferring higher quality values, empty string is returned if response should not be compressed
+func acceptedEncoding(r *http.Request) string {
+	qualities := make(map[string]float64)
+	for _, value := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(value), ";")
+		if len(coding) == 0 {
+			continue
+		}
+
+		quality := 1.0
+		if name, q, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			var err error
+			if quality, err = strconv.ParseFloat(strings.TrimSpace(q), 64); err != nil {
+				continue
+			}
+		}
+		qualities[strings.ToLower(coding)] = quality
+	}
+
+	var (
+		best        string
+		bestQuality float64
+	)
+	for _, encoding := range compressionEncodings {
+		quality, ok := qualities[encoding]
+		if !ok {
+			quality = qualities["*"]
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+
+	return best
+}
+
+// newCompressor returns writer compressing data with given content coding,
+// deflate is zlib wrapped as RFC 9110 defines it, not a raw DEFLATE stream
+func newCompressor(encoding string, w io.Writer) io.WriteCloser {
+	if encoding == "deflate" {
+		return zlib.NewWriter(w)
+	}
+	return gzip.NewWriter(w)
+}
+
+// compressWriter buffers response body until it reaches minBytes and compresses it from that point,
+// smaller responses and responses flushed before reaching minBytes are passed through unchanged
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minBytes int
+
+	status     int
+	buf        []byte
+	started    bool
+	compressor io.WriteCloser
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.started || cw.status != 0 {
+		return
+	}
+
+	// informational responses are not final, pass them through as is
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	cw.status = status
+	if status == http.StatusNoContent || status == http.StatusNotModified || status == http.StatusSwitchingProtocols {
+		cw.start(false)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.started {
+		if cw.compressor != nil {
+			return cw.compressor.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.minBytes {
+		if err := cw.start(true); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// start writes response header and buffered body, compressing it if requested
+// and response is not encoded by handler already
+func (cw *compressWriter) start(compress bool) error {
+	cw.started = true
+
+	header := cw.Header()
+	if compress && len(header.Get("Content-Encoding")) == 0 {
+		// compressed body would be sniffed as binary, so detect content type from plain body
+		if len(header.Get("Content-Type")) == 0 {
+			header.Set("Content-Type", http.DetectContentType(cw.buf))
+		}
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", cw.encoding)
+		cw.compressor = newCompressor(cw.encoding, cw.ResponseWriter)
+	}
+
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+
+	_, err := cw.Write(buf)
+	return err
+}
+
+func (cw *compressWriter) Flush() {
+	if !cw.started {
+		cw.start(false)
+	}
+
+	if flusher, ok := cw.compressor.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (cw *compressWriter) close() error {
+	if !cw.started {
+		if err := cw.start(false); err != nil {
+			return err
+		}
+	}
+
+	if cw.compressor != nil {
+		return cw.compressor.Close()
+	}
+	return nil
+}
+
+// compress middleware compresses responses of at least compression_min_bytes with gzip or deflate
+// depending on client Accept-Encoding header, if enable_compression is set
+func (h *Handler) compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := requestSettings(r)
+		if !cfg.compression {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := acceptedEncoding(r)
+		if len(encoding) == 0 || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, minBytes: cfg.compressionMinBytes}
+		defer func() {
+			if err := cw.close(); err != nil {
+				h.requestLogger(r).Debugw("Unable to complete compressed response", "err", err)
+			}
+		}()
+
+		next.ServeHTTP(cw, r)
+	})
+}
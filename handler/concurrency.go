@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"github.com/spf13/viper"
+	"net/http"
+)
+
+// newConcurrencyLimiter returns semaphore sized by max_concurrent_requests, or nil if limit is not set
+func newConcurrencyLimiter() chan struct{} {
+	if limit := viper.GetInt("max_concurrent_requests"); limit > 0 {
+		return make(chan struct{}, limit)
+	}
+	return nil
+}
+
+// limitConcurrency rejects requests with 503 while max_concurrent_requests are being served,
+// requests are never queued, so overloaded instance answers immediately
+func (h *Handler) limitConcurrency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.concurrency == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		select {
+		case h.concurrency <- struct{}{}:
+			defer func() { <-h.concurrency }()
+			next.ServeHTTP(w, r)
+		default:
+			w.Header().Set("Retry-After", "1")
+			writeError(w, r, http.StatusServiceUnavailable, "too many concurrent requests")
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"github.com/spf13/viper"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	configSourceEnv     = "env"
+	configSourceFile    = "config_file"
+	configSourceFlag    = "flag"
+	configSourceDefault = "default"
+)
+
+var secretConfigKeyParts = []string{"password", "secret", "token"}
+
+// secretConfigKey tells whether config value must not be exposed
+func secretConfigKey(key string) bool {
+	if strings.HasPrefix(key, "auth_") || key == "tls_key" {
+		return true
+	}
+
+	for _, part := range secretConfigKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// configSource guesses where effective config value comes from, following viper precedence.
+// Flags can not be told apart from values set in code, and flags overriding env are reported as env
+func configSource(key string) string {
+	if _, ok := os.LookupEnv(strings.ToUpper(key)); ok {
+		return configSourceEnv
+	}
+	if viper.InConfig(key) {
+		return configSourceFile
+	}
+	if viper.IsSet(key) {
+		return configSourceFlag
+	}
+	return configSourceDefault
+}
+
+// envDependentValues returns effective values of keys decided by env unless configured explicitly
+func (h *Handler) envDependentValues() map[string]any {
+	return map[string]any{
+		"trace_sample_ratio":     h.traceSampleRatio(),
+		"cors_allow_credentials": h.corsAllowCredentials(),
+	}
+}
+
+// configValue returns resolved config value, secret values are redacted unless they are empty
+func (h *Handler) configValue(key string, envValues map[string]any) any {
+	if secretConfigKey(key) && len(viper.GetString(key)) > 0 {
+		return redactedValue
+	}
+	if value, ok := envValues[key]; ok {
+		return value
+	}
+	return viper.Get(key)
+}
+
+// EffectiveConfig returns resolved configuration values, secret values are redacted
+func (h *Handler) EffectiveConfig() map[string]any {
+	envValues := h.envDependentValues()
+	values := make(map[string]any)
+	for _, key := range viper.AllKeys() {
+		values[key] = h.configValue(key, envValues)
+	}
+	return values
+}
+
+// effectiveConfig returns resolved config values grouped by source, secret values are redacted
+func (h *Handler) effectiveConfig() map[string]map[string]any {
+	envValues := h.envDependentValues()
+	sources := make(map[string]map[string]any)
+	for _, key := range viper.AllKeys() {
+		source := configSource(key)
+		if sources[source] == nil {
+			sources[source] = make(map[string]any)
+		}
+		sources[source][key] = h.configValue(key, envValues)
+	}
+	return sources
+}
+
+// configHandler responds with effective non-secret configuration
+func (h *Handler) configHandler(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, map[string]any{
+		"config_file":  viper.ConfigFileUsed(),
+		"sources":      h.effectiveConfig(),
+		"listen_addrs": h.boundListenAddrs(),
+	})
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"github.com/spf13/viper"
+	"testing"
+)
+
+func TestEffectiveConfigRedaction(t *testing.T) {
+	viper.Set("auth_password", "secret")
+	viper.Set("auth_bearer_token", "")
+	viper.Set("json_indent", "\t")
+	defer func() {
+		viper.Set("auth_password", nil)
+		viper.Set("auth_bearer_token", nil)
+		viper.Set("json_indent", nil)
+	}()
+
+	values := new(Handler).effectiveConfig()[configSourceFlag]
+	if values["auth_password"] != redactedValue {
+		t.Errorf("Expected auth_password to be redacted, got: %v", values["auth_password"])
+	}
+	if values["auth_bearer_token"] != "" {
+		t.Errorf("Expected empty auth_bearer_token to be kept, got: %v", values["auth_bearer_token"])
+	}
+	if values["json_indent"] != "\t" {
+		t.Errorf("Expected json_indent to be exposed, got: %v", values["json_indent"])
+	}
+}
+
+func TestEffectiveConfigEnvDefaults(t *testing.T) {
+	viper.Set("env", "prod")
+	viper.Set("trace_sample_ratio", -1)
+	viper.Set("cors_allow_credentials", corsAllowCredentialsAuto)
+	defer func() {
+		viper.Set("env", nil)
+		viper.Set("trace_sample_ratio", nil)
+		viper.Set("cors_allow_credentials", nil)
+	}()
+
+	values := new(Handler).EffectiveConfig()
+	if ratio := values["trace_sample_ratio"]; ratio != defaultProductionSampleRatio {
+		t.Errorf("Expected effective production sample ratio, got: %v", ratio)
+	}
+	if allow := values["cors_allow_credentials"]; allow != false {
+		t.Errorf("Expected credentials to be disallowed in production, got: %v", allow)
+	}
+}
+
+func TestSecretConfigKey(t *testing.T) {
+	cases := map[string]bool{
+		"auth_username":     true,
+		"auth_bearer_token": true,
+		"tls_key":           true,
+		"tls_cert":          false,
+		"otlp_endpoint":     false,
+		"api_secret":        true,
+		"listen_addr":       false,
+	}
+
+	for key, expected := range cases {
+		if secret := secretConfigKey(key); secret != expected {
+			t.Errorf("Expected %s secret to be %t, got %t", key, expected, secret)
+		}
+	}
+}
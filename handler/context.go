@@ -0,0 +1,52 @@
+package handler
+
+import "context"
+
+// ctxKey is an unexported type for request context keys, preventing collisions with other packages
+type ctxKey string
+
+const (
+	hostCtxKey          ctxKey = "host"
+	pathCtxKey          ctxKey = "path"
+	remoteAddrCtxKey    ctxKey = "remote_addr"
+	xForwardedForCtxKey ctxKey = "x_forwarded_for"
+	clientIPCtxKey      ctxKey = "client_ip"
+	settingsCtxKey      ctxKey = "settings"
+	requestIDCtxKey     ctxKey = "request_id"
+	origMethodCtxKey    ctxKey = "original_method"
+)
+
+func stringFromContext(ctx context.Context, key ctxKey) string {
+	value, _ := ctx.Value(key).(string)
+	return value
+}
+
+// HostFromContext returns request host stored by Handler
+func HostFromContext(ctx context.Context) string {
+	return stringFromContext(ctx, hostCtxKey)
+}
+
+// PathFromContext returns request URL path stored by Handler
+func PathFromContext(ctx context.Context) string {
+	return stringFromContext(ctx, pathCtxKey)
+}
+
+// RemoteAddrFromContext returns request remote address stored by Handler
+func RemoteAddrFromContext(ctx context.Context) string {
+	return stringFromContext(ctx, remoteAddrCtxKey)
+}
+
+// XForwardedForFromContext returns request X-Forwarded-For header value stored by Handler
+func XForwardedForFromContext(ctx context.Context) string {
+	return stringFromContext(ctx, xForwardedForCtxKey)
+}
+
+// ClientIPFromContext returns request client IP address resolved by Handler
+func ClientIPFromContext(ctx context.Context) string {
+	return stringFromContext(ctx, clientIPCtxKey)
+}
+
+// RequestIDFromContext returns request ID received with X-Request-Id header or generated by Handler
+func RequestIDFromContext(ctx context.Context) string {
+	return stringFromContext(ctx, requestIDCtxKey)
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+)
+
+// requestCookies returns request cookies name to value map, redact_cookies values are masked
+func requestCookies(r *http.Request, cfg *settings) map[string]string {
+	cookies := make(map[string]string)
+	for _, cookie := range r.Cookies() {
+		if containsFold(cfg.redactCookies, cookie.Name) {
+			cookies[cookie.Name] = redactedValue
+			continue
+		}
+		cookies[cookie.Name] = cookie.Value
+	}
+	return cookies
+}
+
+// cookiesHandler responds with request cookies
+func (h *Handler) cookiesHandler(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, map[string]any{
+		"cookies": requestCookies(r, requestSettings(r)),
+	})
+}
+
+// setCookiesHandler sets cookie for every query parameter and redirects to /cookies
+func (h *Handler) setCookiesHandler(w http.ResponseWriter, r *http.Request) {
+	for name, values := range r.URL.Query() {
+		http.SetCookie(w, &http.Cookie{
+			Name:  name,
+			Value: values[len(values)-1],
+			Path:  "/",
+		})
+	}
+
+	http.Redirect(w, r, h.routeURL("/cookies"), http.StatusFound)
+}
+
+// deleteCookiesHandler expires cookies named by query parameters and redirects to /cookies
+func (h *Handler) deleteCookiesHandler(w http.ResponseWriter, r *http.Request) {
+	for name := range r.URL.Query() {
+		http.SetCookie(w, &http.Cookie{
+			Name:   name,
+			Path:   "/",
+			MaxAge: -1,
+		})
+	}
+
+	http.Redirect(w, r, h.routeURL("/cookies"), http.StatusFound)
+}
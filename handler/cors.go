@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"github.com/spf13/viper"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// corsAllowCredentialsAuto leaves cors_allow_credentials to be decided by env
+const corsAllowCredentialsAuto = "auto"
+
+func corsAllowedMethods() []string {
+	if methods := viper.GetStringSlice("cors_allowed_methods"); len(methods) > 0 {
+		return methods
+	}
+	return allowedMethods
+}
+
+func corsAllowedHeaders() []string {
+	if headers := viper.GetStringSlice("cors_allowed_headers"); len(headers) > 0 {
+		return headers
+	}
+	return allowedHeaders
+}
+
+// corsAllowCredentials parses cors_allow_credentials, auto or not set value allows credentials
+// except production where they are disabled
+func (h *Handler) corsAllowCredentials() bool {
+	if allow, err := strconv.ParseBool(viper.GetString("cors_allow_credentials")); err == nil {
+		return allow
+	}
+	return !h.isProduction()
+}
+
+// corsAllowedOrigins defaults to reflect any origin, or to wildcard in production,
+// so arbitrary origins never get credentialed access unless configured explicitly
+func (h *Handler) corsAllowedOrigins() []string {
+	if origins := viper.GetStringSlice("cors_allowed_origins"); len(origins) > 0 {
+		return origins
+	}
+	if h.isProduction() {
+		return []string{"*"}
+	}
+	return nil
+}
+
+// corsAllowedOrigin returns value for Access-Control-Allow-Origin header
+// or empty string if origin is not allowed.
+// Any origin is reflected back if cors_allowed_origins is not set
+func corsAllowedOrigin(cfg *settings, origin string) string {
+	if len(cfg.corsAllowedOrigins) == 0 {
+		return origin
+	}
+
+	for _, allowed := range cfg.corsAllowedOrigins {
+		if allowed == "*" {
+			// wildcard is not allowed by browsers for credentialed requests
+			if cfg.corsAllowCredentials {
+				return origin
+			}
+			return "*"
+		}
+
+		if strings.EqualFold(allowed, origin) {
+			return origin
+		}
+	}
+
+	return ""
+}
+
+// setCORSHeaders sets request headers for AJAX requests
+func setCORSHeaders(w http.ResponseWriter, r *http.Request, cfg *settings) {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return
+	}
+
+	allowedOrigin := corsAllowedOrigin(cfg, origin)
+	if allowedOrigin == "" {
+		return
+	}
+
+	if allowedOrigin != "*" {
+		w.Header().Add("Vary", "Origin")
+	}
+
+	if cfg.corsAllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+	w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(cfg.corsAllowedMethods, headersSep))
+	w.Header().Set("Access-Control-Allow-Headers", strings.Join(cfg.corsAllowedHeaders, headersSep))
+}
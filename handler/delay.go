@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/viper"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultMaxDelay = 60 * time.Second
+)
+
+func maxDelay() time.Duration {
+	if limit := viper.GetDuration("max_delay"); limit > 0 {
+		return limit
+	}
+	return defaultMaxDelay
+}
+
+// delayHandler sleeps for the requested duration, capped by max_delay, and responds with request echo.
+// Nothing is written if client disconnects or request_timeout is exceeded while waiting
+func (h *Handler) delayHandler(w http.ResponseWriter, r *http.Request) {
+	delay, err := time.ParseDuration(chi.URLParam(r, "duration"))
+	if err != nil || delay < 0 {
+		writeError(w, r, http.StatusBadRequest, "invalid delay duration")
+		return
+	}
+
+	if limit := requestSettings(r).maxDelay; delay > limit {
+		delay = limit
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-r.Context().Done():
+		h.requestLogger(r).Debugw("Request is done before delay elapsed", "delay", delay.String(), "err", r.Context().Err())
+		return
+	case <-timer.C:
+	}
+
+	results, ok := h.echoResults(w, r)
+	if !ok {
+		return
+	}
+	results.Delay = delay.String()
+	writeResponse(w, r, results)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// EchoHeader is a request header as reported by echo handlers
+type EchoHeader struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// EchoFile describes multipart form file, file contents are not reported
+type EchoFile struct {
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+}
+
+// EchoResponse is request echo reported by mainHandler and other echo routes
+type EchoResponse struct {
+	Method     string              `json:"method"`
+	URL        *url.URL            `json:"url"`
+	Proto      string              `json:"proto"`
+	ProtoMajor int                 `json:"proto_major"`
+	ProtoMinor int                 `json:"proto_minor"`
+	Headers    []EchoHeader        `json:"headers"`
+	Cookies    map[string]string   `json:"cookies"`
+	Query      map[string][]string `json:"query"`
+	UserAgent  string              `json:"user_agent"`
+	RemoteAddr string              `json:"remote_addr"`
+	ClientIP   string              `json:"client_ip"`
+	RequestID  string              `json:"request_id"`
+	TLS        map[string]any      `json:"tls,omitempty"`
+	TraceID    string              `json:"trace_id,omitempty"`
+	SpanID     string              `json:"span_id,omitempty"`
+
+	// headers are truncated to max_echoed_headers after sorting, total count is reported if they are
+	HeadersTruncated bool `json:"headers_truncated,omitempty"`
+	HeadersTotal     int  `json:"headers_total,omitempty"`
+
+	// method request is sent with, if it is overridden by X-HTTP-Method-Override header
+	OriginalMethod string `json:"original_method,omitempty"`
+
+	// request body, decoded according to its Content-Type
+	Body              any                   `json:"body,omitempty"`
+	BodyBase64        string                `json:"body_base64,omitempty"`
+	BodyEncoding      string                `json:"body_encoding,omitempty"`
+	BodyDecodingError string                `json:"body_decoding_error,omitempty"`
+	BodyRaw           string                `json:"body_raw,omitempty"`
+	ContentEncoding   string                `json:"content_encoding,omitempty"`
+	Form              map[string][]string   `json:"form,omitempty"`
+	Files             map[string][]EchoFile `json:"files,omitempty"`
+
+	// bodyTooLarge is set if request body exceeds max_body_bytes
+	bodyTooLarge bool
+
+	// route specific details, path_suffix is a pointer as it is reported by /anything even if empty
+	Delay      string  `json:"delay,omitempty"`
+	Gzipped    bool    `json:"gzipped,omitempty"`
+	Deflated   bool    `json:"deflated,omitempty"`
+	PathSuffix *string `json:"path_suffix,omitempty"`
+}
+
+// HeaderList represents headers the way echo handlers report them, as a list of name and values pairs
+// sorted by name, so output is deterministic. Values keep the order they were received in
+func HeaderList(header http.Header) []EchoHeader {
+	var headers []EchoHeader
+	for name, values := range header {
+		headers = append(headers, EchoHeader{Name: name, Values: values})
+	}
+
+	sort.Slice(headers, func(i, j int) bool {
+		return headers[i].Name < headers[j].Name
+	})
+	return headers
+}
+
+// echoHandler reflects request body back verbatim with the same Content-Type and X-Echoed-Length
+// set to the number of bytes read, body is not decoded. Bodies over max_body_bytes are rejected with 413
+func (h *Handler) echoHandler(w http.ResponseWriter, r *http.Request) {
+	limit := requestSettings(r).maxBodyBytes
+
+	// body is copied up to the limit first, so X-Echoed-Length is known before response is written
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, http.MaxBytesReader(w, r.Body, limit))
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		writeBodyTooLarge(w, r, limit)
+		return
+	}
+	if err != nil {
+		h.requestLogger(r).Errorw("Unable to read body data", "err", err)
+		writeError(w, r, http.StatusBadRequest, "unable to read body: "+err.Error())
+		return
+	}
+
+	if contentType := r.Header.Get("Content-Type"); len(contentType) > 0 {
+		w.Header().Set("Content-Type", contentType)
+	} else {
+		// prevent sniffing, so body is returned exactly as it was sent
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+	w.Header().Set("X-Echoed-Length", strconv.FormatInt(n, 10))
+	w.Header().Set("Content-Length", strconv.FormatInt(n, 10))
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, &buf); err != nil {
+		h.requestLogger(r).Debugw("Unable to write echoed body", "err", err)
+	}
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestHeaderListSorted(t *testing.T) {
+	header := http.Header{}
+	header.Add("X-Zulu", "1")
+	header.Add("Accept", "text/plain")
+	header.Add("X-Alpha", "second")
+	header.Add("Content-Type", "application/json")
+	header.Add("X-Alpha", "first")
+
+	expected := []EchoHeader{
+		{Name: "Accept", Values: []string{"text/plain"}},
+		{Name: "Content-Type", Values: []string{"application/json"}},
+		{Name: "X-Alpha", Values: []string{"second", "first"}},
+		{Name: "X-Zulu", Values: []string{"1"}},
+	}
+
+	// map iteration order is random, so repeat to make unsorted output unlikely to pass
+	for i := 0; i < 10; i++ {
+		if headers := HeaderList(header); !reflect.DeepEqual(headers, expected) {
+			t.Fatalf("Expected sorted headers %v, got: %v", expected, headers)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+)
+
+// gzipHandler responds with request echo compressed with gzip regardless of Accept-Encoding,
+// so clients can verify they decompress responses correctly
+func (h *Handler) gzipHandler(w http.ResponseWriter, r *http.Request) {
+	results, ok := h.echoResults(w, r)
+	if !ok {
+		return
+	}
+	results.Gzipped = true
+	writeEncodedResponse(w, r, "gzip", results)
+}
+
+// deflateHandler responds with request echo compressed with deflate regardless of Accept-Encoding
+func (h *Handler) deflateHandler(w http.ResponseWriter, r *http.Request) {
+	results, ok := h.echoResults(w, r)
+	if !ok {
+		return
+	}
+	results.Deflated = true
+	writeEncodedResponse(w, r, "deflate", results)
+}
+
+// writeEncodedResponse writes value compressed with given content coding,
+// body is compressed in advance to report accurate Content-Length
+func writeEncodedResponse(w http.ResponseWriter, r *http.Request, encoding string, v any) {
+	contentType, response, err := marshalResponse(r, v)
+	if err != nil {
+		writeMarshalError(w, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	compressor := newCompressor(encoding, &buf)
+	if _, err := compressor.Write(response); err != nil {
+		writeMarshalError(w, err)
+		return
+	}
+	if err := compressor.Close(); err != nil {
+		writeMarshalError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Encoding", encoding)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"github.com/spf13/viper"
+	"strings"
+)
+
+// productionEnvs are env values treated as production,
+// any other value, e.g. dev, stage or test, is treated as non-production
+var productionEnvs = []string{
+	"main",
+	"prod",
+	"production",
+}
+
+// isProduction reports whether handler is running in production environment.
+// Production enables non-development logging, info log level,
+// 10% trace sampling and disables credentialed CORS by default
+func (h *Handler) isProduction() bool {
+	env := strings.TrimSpace(viper.GetString("env"))
+	for _, prodEnv := range productionEnvs {
+		if strings.EqualFold(env, prodEnv) {
+			return true
+		}
+	}
+	return false
+}
+
+// EnvDefaults returns defaults of keys decided by env, flags of these keys default
+// to a value leaving the decision to env, so they are not written as defaults as is
+func (h *Handler) EnvDefaults() map[string]any {
+	if h.isProduction() {
+		return map[string]any{
+			"trace_sample_ratio":     defaultProductionSampleRatio,
+			"cors_allow_credentials": false,
+		}
+	}
+	return map[string]any{
+		"trace_sample_ratio":     defaultSampleRatio,
+		"cors_allow_credentials": true,
+	}
+}
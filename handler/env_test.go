@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"github.com/spf13/viper"
+	"testing"
+)
+
+func TestEnvDependentDefaults(t *testing.T) {
+	defer func() {
+		for _, key := range []string{"env", "trace_sample_ratio", "cors_allow_credentials"} {
+			viper.Set(key, nil)
+		}
+	}()
+
+	cases := []struct {
+		env         string
+		ratio       any
+		credentials any
+		expectRatio float64
+		expectCreds bool
+	}{
+		{env: "dev", expectRatio: 1, expectCreds: true},
+		{env: "prod", expectRatio: 0.1, expectCreds: false},
+		{env: "production", ratio: -1, credentials: "auto", expectRatio: 0.1, expectCreds: false},
+		{env: "dev", ratio: 0, credentials: false, expectRatio: 0, expectCreds: false},
+		{env: "main", ratio: 0.5, credentials: "true", expectRatio: 0.5, expectCreds: true},
+	}
+
+	h := new(Handler)
+	for _, c := range cases {
+		viper.Set("env", c.env)
+		viper.Set("trace_sample_ratio", c.ratio)
+		viper.Set("cors_allow_credentials", c.credentials)
+
+		if ratio := h.traceSampleRatio(); ratio != c.expectRatio {
+			t.Errorf("Expected %v sample ratio for env %s and %v, got: %v", c.expectRatio, c.env, c.ratio, ratio)
+		}
+		if allow := h.corsAllowCredentials(); allow != c.expectCreds {
+			t.Errorf("Expected credentials allowed %t for env %s and %v, got: %t", c.expectCreds, c.env, c.credentials, allow)
+		}
+	}
+}
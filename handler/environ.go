@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+	"os"
+	"strings"
+)
+
+// allowedEnviron returns process environment variables with names matching one of allowlisted prefixes,
+// variables named like secrets are redacted even if allowlisted, empty allowlist returns nothing
+func allowedEnviron(prefixes []string) map[string]string {
+	environ := make(map[string]string)
+	if len(prefixes) == 0 {
+		return environ
+	}
+
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		for _, prefix := range prefixes {
+			if len(prefix) == 0 || !strings.HasPrefix(name, prefix) {
+				continue
+			}
+
+			if secretConfigKey(strings.ToLower(name)) && len(value) > 0 {
+				value = redactedValue
+			}
+			environ[name] = value
+			break
+		}
+	}
+	return environ
+}
+
+// envHandler responds with process environment variables allowed by env_echo_allowlist
+func (h *Handler) envHandler(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, map[string]any{
+		"env": allowedEnviron(requestSettings(r).envEchoAllowlist),
+	})
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"golang.org/x/net/http/httpguts"
+	"net/http"
+	"strings"
+)
+
+const (
+	redactedValue = "***"
+
+	defaultMaxHeaderCount = 100
+)
+
+var defaultRedactHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"X-CSRF-Token",
+}
+
+func redactHeaders() []string {
+	if viper.IsSet("redact_headers") {
+		return viper.GetStringSlice("redact_headers")
+	}
+	return defaultRedactHeaders
+}
+
+func maxHeaderBytes() int {
+	if limit := viper.GetInt("max_header_bytes"); limit > 0 {
+		return limit
+	}
+	return http.DefaultMaxHeaderBytes
+}
+
+func maxHeaderCount() int {
+	if limit := viper.GetInt("max_header_count"); limit > 0 {
+		return limit
+	}
+	return defaultMaxHeaderCount
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterHeaders returns a copy of request headers safe to be echoed:
+// only header_allowlist headers are kept if it is set, and redact_headers values are masked
+func filterHeaders(header http.Header, cfg *settings) http.Header {
+	filtered := make(http.Header, len(header))
+	for name, values := range header {
+		if len(cfg.headerAllowlist) > 0 && !containsFold(cfg.headerAllowlist, name) {
+			continue
+		}
+
+		if containsFold(cfg.redactHeaders, name) {
+			masked := make([]string, len(values))
+			for i := range masked {
+				masked[i] = redactedValue
+			}
+			filtered[name] = masked
+			continue
+		}
+
+		filtered[name] = values
+	}
+
+	return filtered
+}
+
+// headersHandler responds with filtered request headers as a flat map
+func (h *Handler) headersHandler(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, map[string]any{
+		"headers": filterHeaders(r.Header, requestSettings(r)),
+	})
+}
+
+func validHeader(name, value string) error {
+	if !httpguts.ValidHeaderFieldName(name) {
+		return fmt.Errorf("invalid header name %q", name)
+	}
+	if !httpguts.ValidHeaderFieldValue(value) {
+		return fmt.Errorf("invalid %s header value", name)
+	}
+	return nil
+}
+
+// parseResponseHeaders parses response_headers name to value map, invalid headers are skipped
+func parseResponseHeaders(values map[string]string) http.Header {
+	header := make(http.Header, len(values))
+	for name, value := range values {
+		if validHeader(name, value) == nil {
+			header.Set(name, value)
+		}
+	}
+	return header
+}
+
+// parseHeaderParams parses list of Name:Value pairs, e.g. passed with ?header= query parameter
+func parseHeaderParams(params []string) (http.Header, error) {
+	header := make(http.Header, len(params))
+	for _, param := range params {
+		name, value, ok := strings.Cut(param, ":")
+		if !ok {
+			return nil, fmt.Errorf("header %q must be in Name:Value format", param)
+		}
+
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if err := validHeader(name, value); err != nil {
+			return nil, err
+		}
+		header.Add(name, value)
+	}
+	return header, nil
+}
+
+// responseHeaders middleware sets response_headers to every response
+func (h *Handler) responseHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for name, values := range requestSettings(r).responseHeaders {
+			w.Header()[name] = values
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitHeaders middleware rejects requests with more than max_header_count header lines,
+// total headers size is limited by server with max_header_bytes
+func (h *Handler) limitHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var count int
+		for _, values := range r.Header {
+			count += len(values)
+		}
+
+		if count > requestSettings(r).maxHeaderCount {
+			writeError(w, r, http.StatusRequestHeaderFieldsTooLarge, "too many request headers")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/viper"
+	"net"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	defaultReadinessCheckTimeout = 2 * time.Second
+
+	readinessStatusOk = "ok"
+)
+
+// ReadinessCheck reports an error if dependency is not available
+type ReadinessCheck func(ctx context.Context) error
+
+// RegisterReadinessCheck adds named check run on every /readyz request,
+// check with the same name is replaced
+func (h *Handler) RegisterReadinessCheck(name string, fn func(ctx context.Context) error) {
+	h.checksMu.Lock()
+	defer h.checksMu.Unlock()
+
+	if h.readinessChecks == nil {
+		h.readinessChecks = make(map[string]ReadinessCheck)
+	}
+	h.readinessChecks[name] = fn
+}
+
+// registerTCPChecks adds dial checks for every readiness_tcp_targets address
+func (h *Handler) registerTCPChecks() {
+	for _, target := range viper.GetStringSlice("readiness_tcp_targets") {
+		address := target
+		h.RegisterReadinessCheck("tcp:"+address, func(ctx context.Context) error {
+			var dialer net.Dialer
+			conn, err := dialer.DialContext(ctx, "tcp", address)
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		})
+	}
+}
+
+func readinessCheckTimeout() time.Duration {
+	if timeout := viper.GetDuration("readiness_check_timeout"); timeout > 0 {
+		return timeout
+	}
+	return defaultReadinessCheckTimeout
+}
+
+// runReadinessChecks runs all registered checks concurrently and returns their statuses
+func (h *Handler) runReadinessChecks(ctx context.Context, timeout time.Duration) (map[string]string, bool) {
+	h.checksMu.RLock()
+	checks := make(map[string]ReadinessCheck, len(h.readinessChecks))
+	for name, fn := range h.readinessChecks {
+		checks[name] = fn
+	}
+	h.checksMu.RUnlock()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]string, len(checks))
+	healthy := true
+
+	for name, fn := range checks {
+		wg.Add(1)
+		go func(name string, fn ReadinessCheck) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			status := readinessStatusOk
+			if err := fn(checkCtx); err != nil {
+				status = fmt.Sprintf("failed: %s", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[name] = status
+			if status != readinessStatusOk {
+				healthy = false
+			}
+		}(name, fn)
+	}
+
+	wg.Wait()
+	return results, healthy
+}
+
+// healthCheck serves liveness probe, responds as long as the process is up
+func (h *Handler) healthCheck(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().Unix()
+	writeResponse(w, r, map[string]any{
+		"alive":      now - runDate.Unix(),
+		"version":    AppVersion,
+		"git_commit": GitCommit,
+		"build_date": BuildDate,
+		"go_version": runtime.Version(),
+		"healthy":    true,
+		"timestamp":  time.Now().Format(time.RFC1123),
+		"limits": map[string]any{
+			"max_header_bytes": maxHeaderBytes(),
+			"max_header_count": requestSettings(r).maxHeaderCount,
+		},
+	})
+}
+
+// readinessCheck serves readiness probe, responds with 503 until server is initialized
+// or if any of registered readiness checks fails
+func (h *Handler) readinessCheck(w http.ResponseWriter, r *http.Request) {
+	ready := h.ready.Load()
+	checks, healthy := h.runReadinessChecks(r.Context(), requestSettings(r).readinessCheckTimeout)
+	ready = ready && healthy
+
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeStatusResponse(w, r, status, map[string]any{
+		"ready":     ready,
+		"checks":    checks,
+		"timestamp": time.Now().Format(time.RFC1123),
+	})
+}
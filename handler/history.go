@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"github.com/spf13/viper"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRequestHistorySize = 100
+	// maxRequestHistorySize caps memory used by request history regardless of configuration
+	maxRequestHistorySize = 10000
+)
+
+func requestHistorySize() int {
+	size := viper.GetInt("request_history_size")
+	if size <= 0 {
+		return defaultRequestHistorySize
+	}
+	if size > maxRequestHistorySize {
+		return maxRequestHistorySize
+	}
+	return size
+}
+
+// requestSummary describes handled request kept in request history
+type requestSummary struct {
+	Time      time.Time `json:"time"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	ClientIP  string    `json:"client_ip"`
+	RequestID string    `json:"request_id"`
+	Duration  string    `json:"duration"`
+}
+
+// requestHistory is a fixed size ring buffer of recently handled requests, safe for concurrent use
+type requestHistory struct {
+	mu      sync.Mutex
+	entries []requestSummary
+	next    int
+	full    bool
+}
+
+func newRequestHistory(size int) *requestHistory {
+	return &requestHistory{entries: make([]requestSummary, size)}
+}
+
+// add records request summary overwriting the oldest one once buffer is full
+func (rh *requestHistory) add(summary requestSummary) {
+	if rh == nil {
+		return
+	}
+
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+
+	rh.entries[rh.next] = summary
+	rh.next = (rh.next + 1) % len(rh.entries)
+	if rh.next == 0 {
+		rh.full = true
+	}
+}
+
+// list returns recorded request summaries, the most recent first
+func (rh *requestHistory) list() []requestSummary {
+	if rh == nil {
+		return nil
+	}
+
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+
+	count := rh.next
+	if rh.full {
+		count = len(rh.entries)
+	}
+
+	summaries := make([]requestSummary, 0, count)
+	for i := 1; i <= count; i++ {
+		summaries = append(summaries, rh.entries[(rh.next-i+len(rh.entries))%len(rh.entries)])
+	}
+	return summaries
+}
+
+// capacity returns maximal number of kept request summaries
+func (rh *requestHistory) capacity() int {
+	if rh == nil {
+		return 0
+	}
+	return len(rh.entries)
+}
+
+// requestHistoryHandler responds with recently handled requests, the most recent first
+func (h *Handler) requestHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	requests := h.history.list()
+	writeResponse(w, r, map[string]any{
+		"requests": requests,
+		"count":    len(requests),
+		"capacity": h.history.capacity(),
+	})
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestRequestHistory(t *testing.T) {
+	history := newRequestHistory(3)
+	if entries := history.list(); len(entries) != 0 {
+		t.Fatalf("Expected empty history, got: %v", entries)
+	}
+
+	for i := 1; i <= 5; i++ {
+		history.add(requestSummary{Path: fmt.Sprintf("/%d", i)})
+	}
+
+	entries := history.list()
+	if len(entries) != 3 {
+		t.Fatalf("Expected history to be capped by 3 entries, got: %d", len(entries))
+	}
+	for i, expected := range []string{"/5", "/4", "/3"} {
+		if entries[i].Path != expected {
+			t.Errorf("Expected %s at %d, got: %s", expected, i, entries[i].Path)
+		}
+	}
+}
+
+func TestRequestHistoryConcurrentAccess(t *testing.T) {
+	history := newRequestHistory(10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				history.add(requestSummary{Path: "/"})
+				history.list()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if entries := history.list(); len(entries) != 10 {
+		t.Errorf("Expected full history, got: %d entries", len(entries))
+	}
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"github.com/spf13/viper"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	unixAddrPrefix = "unix:"
+
+	defaultListenNetwork = "tcp"
+)
+
+// listenNetworks are supported listen_network values: tcp binds wildcard address to both IPv4 and IPv6,
+// tcp4 and tcp6 bind it to a single family only. IP addresses are bound to their own family regardless
+var listenNetworks = []string{"tcp", "tcp4", "tcp6"}
+
+func listenNetwork() string {
+	if network := viper.GetString("listen_network"); len(network) > 0 {
+		return strings.ToLower(network)
+	}
+	return defaultListenNetwork
+}
+
+// listen creates server listener, listen_addr prefixed with unix: is a Unix domain socket path.
+// Socket file is removed by listener once it is closed on shutdown.
+// TCP listeners are bound with listen_network and SO_REUSEPORT if reuse_port is enabled
+func listen(addr string) (net.Listener, error) {
+	if !strings.HasPrefix(addr, unixAddrPrefix) {
+		var lc net.ListenConfig
+		if viper.GetBool("reuse_port") {
+			lc.Control = reusePortControl
+		}
+		return lc.Listen(context.Background(), listenNetwork(), addr)
+	}
+
+	path := strings.TrimPrefix(addr, unixAddrPrefix)
+	// stale socket is left if previous process was not shut down gracefully
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	return net.Listen("unix", path)
+}
+
+// listenerKey identifies listener socket, it is reused by restarted server only if neither address nor network has changed
+func listenerKey(addr string) string {
+	if strings.HasPrefix(addr, unixAddrPrefix) {
+		return addr
+	}
+	return listenNetwork() + " " + addr
+}
+
+// listenAll returns listener for every server. Sockets of current listeners are reused for unchanged addresses,
+// the rest are bound anew. If any of them fails, sockets bound by this call are closed
+func listenAll(servers []*http.Server, current map[string]*sharedListener) (map[string]*sharedListener, []net.Listener, error) {
+	shared := make(map[string]*sharedListener, len(servers))
+	listeners := make([]net.Listener, 0, len(servers))
+	for _, server := range servers {
+		key := listenerKey(server.Addr)
+		sl, ok := shared[key]
+		if !ok {
+			sl, ok = current[key]
+		}
+		if !ok {
+			ln, err := listen(server.Addr)
+			if err != nil {
+				closeUnusedListeners(shared, current)
+				return nil, nil, err
+			}
+			sl = newSharedListener(ln)
+		}
+		shared[key] = sl
+		listeners = append(listeners, sl.view())
+	}
+	return shared, listeners, nil
+}
+
+// closeUnusedListeners closes sockets of listeners which are not kept in use
+func closeUnusedListeners(listeners, kept map[string]*sharedListener) {
+	for key, sl := range listeners {
+		if kept[key] != sl {
+			sl.Close()
+		}
+	}
+}
+
+// acceptResult is a connection or an error returned by listener socket
+type acceptResult struct {
+	conn net.Conn
+	err  error
+}
+
+// sharedListener accepts connections on a single socket and hands them over to servers serving its views.
+// Server shutdown closes its view only, so listener restart swaps servers on the same socket
+// and connections queued on it are never dropped
+type sharedListener struct {
+	net.Listener
+	accepted  chan acceptResult
+	closing   chan struct{}
+	closeOnce sync.Once
+}
+
+func newSharedListener(ln net.Listener) *sharedListener {
+	sl := &sharedListener{
+		Listener: ln,
+		accepted: make(chan acceptResult),
+		closing:  make(chan struct{}),
+	}
+	go sl.acceptLoop()
+	return sl
+}
+
+// acceptLoop accepts connection only once previous one is taken by any of servers, so the rest stay queued on the socket
+func (sl *sharedListener) acceptLoop() {
+	for {
+		conn, err := sl.Listener.Accept()
+		select {
+		case sl.accepted <- acceptResult{conn: conn, err: err}:
+		case <-sl.closing:
+			if conn != nil {
+				conn.Close()
+			}
+			return
+		}
+	}
+}
+
+// Close closes listener socket, servers serving its views stop accepting connections
+func (sl *sharedListener) Close() error {
+	var err error
+	sl.closeOnce.Do(func() {
+		close(sl.closing)
+		err = sl.Listener.Close()
+	})
+	return err
+}
+
+func (sl *sharedListener) view() net.Listener {
+	return &listenerView{shared: sl, closed: make(chan struct{})}
+}
+
+// listenerView is a server own listener of shared socket
+type listenerView struct {
+	shared    *sharedListener
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (v *listenerView) Accept() (net.Conn, error) {
+	// closed view must not take connections from the rest of servers
+	select {
+	case <-v.closed:
+		return nil, net.ErrClosed
+	default:
+	}
+
+	select {
+	case res := <-v.shared.accepted:
+		return res.conn, res.err
+	case <-v.closed:
+		return nil, net.ErrClosed
+	case <-v.shared.closing:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close stops view from accepting connections, socket is left open for other servers
+func (v *listenerView) Close() error {
+	v.closeOnce.Do(func() {
+		close(v.closed)
+	})
+	return nil
+}
+
+func (v *listenerView) Addr() net.Addr {
+	return v.shared.Addr()
+}
+
+// boundAddr returns address listener is bound to, e.g. with port chosen by system for :0,
+// Unix domain socket paths are prefixed with unix: the same way listen_addr is
+func boundAddr(ln net.Listener) string {
+	if ln.Addr().Network() == "unix" {
+		return unixAddrPrefix + ln.Addr().String()
+	}
+	return ln.Addr().String()
+}
+
+func boundAddrs(listeners []net.Listener) []string {
+	addrs := make([]string, 0, len(listeners))
+	for _, ln := range listeners {
+		addrs = append(addrs, boundAddr(ln))
+	}
+	return addrs
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"github.com/spf13/viper"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestListenNetwork(t *testing.T) {
+	if ln, err := net.Listen("tcp6", "[::1]:0"); err != nil {
+		t.Skipf("IPv6 loopback is not available: %s", err)
+	} else {
+		ln.Close()
+	}
+
+	cases := []struct {
+		network string
+		addr    string
+		ipv4    bool
+		ipv6    bool
+	}{
+		{network: "tcp", addr: ":0", ipv4: true, ipv6: true},
+		{network: "tcp", addr: "[::1]:0", ipv6: true},
+		{network: "tcp", addr: "127.0.0.1:0", ipv4: true},
+		{network: "tcp4", addr: ":0", ipv4: true},
+		{network: "tcp6", addr: ":0", ipv6: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.network+" "+c.addr, func(t *testing.T) {
+			viper.Set("listen_network", c.network)
+			defer viper.Set("listen_network", nil)
+
+			ln, err := listen(c.addr)
+			if err != nil {
+				t.Fatalf("Unable to listen: %s", err)
+			}
+			defer ln.Close()
+			port := strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)
+
+			for _, family := range []struct {
+				host     string
+				expected bool
+			}{
+				{host: "127.0.0.1", expected: c.ipv4},
+				{host: "::1", expected: c.ipv6},
+			} {
+				conn, err := net.DialTimeout("tcp", net.JoinHostPort(family.host, port), time.Second)
+				if err == nil {
+					conn.Close()
+				}
+				if connected := err == nil; connected != family.expected {
+					t.Errorf("Expected connection over %s to succeed: %t, got error: %v", family.host, family.expected, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+)
+
+// defaultLogLevel is info for production and debug otherwise
+func (h *Handler) defaultLogLevel() zapcore.Level {
+	if h.isProduction() {
+		return zapcore.InfoLevel
+	}
+	return zapcore.DebugLevel
+}
+
+// configuredLogLevel parses log_level, defaults to defaultLogLevel
+func (h *Handler) configuredLogLevel() (zapcore.Level, error) {
+	value := viper.GetString("log_level")
+	if len(value) == 0 {
+		return h.defaultLogLevel(), nil
+	}
+
+	level, err := zapcore.ParseLevel(value)
+	if err != nil {
+		return level, fmt.Errorf("invalid log_level '%s', expected one of debug, info, warn, error", value)
+	}
+
+	return level, nil
+}
+
+// initLogger builds configured logger. Invalid logger configuration never prevents server from starting,
+// minimal stderr logger with default level is used instead and the problem is logged with it
+func (h *Handler) initLogger() {
+	var l *zap.Logger
+	level, err := h.configuredLogLevel()
+	if err == nil {
+		// level is kept on handler, so it can be adjusted at runtime
+		h.logLevel = zap.NewAtomicLevelAt(level)
+		l, err = h.newLogger(h.logLevel)
+	}
+	if err != nil {
+		h.logLevel = zap.NewAtomicLevelAt(h.defaultLogLevel())
+		l = fallbackLogger(h.logLevel)
+	}
+
+	zap.ReplaceGlobals(l)
+	h.logger = l.Sugar()
+
+	if err != nil {
+		h.logger.Warnw("Invalid logger configuration, using fallback stderr logger", "level", h.logLevel.String(), "err", err)
+	}
+}
+
+// fallbackLogger writes plain console output to stderr, it has no options which could fail
+func fallbackLogger(level zap.AtomicLevel) *zap.Logger {
+	return zap.New(zapcore.NewCore(
+		zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()),
+		zapcore.Lock(os.Stderr),
+		level,
+	))
+}
+
+// newLogger builds logger configured with log_json and log_stacktrace at level
+func (h *Handler) newLogger(level zap.AtomicLevel) (*zap.Logger, error) {
+	cfg := zap.NewDevelopmentConfig()
+	cfg.Development = !h.isProduction()
+	cfg.DisableStacktrace = !viper.GetBool("log_stacktrace")
+
+	if viper.GetBool("log_json") {
+		cfg.Encoding = "json"
+	} else {
+		cfg.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+
+	cfg.Level = level
+
+	return cfg.Build()
+}
+
+// applyLogLevel sets log level from reloaded config, invalid values are reported and ignored
+func (h *Handler) applyLogLevel() {
+	level, err := h.configuredLogLevel()
+	if err != nil {
+		h.logger.Errorw("Unable to apply log level", "err", err)
+		return
+	}
+
+	if level != h.logLevel.Level() {
+		h.logLevel.SetLevel(level)
+		h.logger.Infow("Log level changed", "level", level.String())
+	}
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"github.com/spf13/viper"
+	"go.uber.org/zap/zapcore"
+	"testing"
+)
+
+func TestInitLoggerInvalidLevel(t *testing.T) {
+	viper.Set("log_level", "verbose")
+	defer viper.Set("log_level", nil)
+
+	h := new(Handler)
+	h.initLogger()
+	if h.logger == nil {
+		t.Fatalf("Invalid log level must fall back to default logger")
+	}
+
+	if level := h.logLevel.Level(); level != zapcore.DebugLevel {
+		t.Errorf("Expected fallback logger to use default debug level, got: %s", level)
+	}
+}
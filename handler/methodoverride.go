@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	methodOverrideHeader = "X-HTTP-Method-Override"
+)
+
+// methodOverride replaces POST request method with X-HTTP-Method-Override header value before routing
+// if allow_method_override is set, so clients restricted to POST can reach other methods.
+// Only allowedMethods are accepted, original method is reported by echo as original_method
+func (h *Handler) methodOverride(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		override := strings.ToUpper(strings.TrimSpace(r.Header.Get(methodOverrideHeader)))
+		if !requestSettings(r).allowMethodOverride || r.Method != http.MethodPost || len(override) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !containsFold(allowedMethods, override) {
+			writeError(w, r, http.StatusBadRequest, fmt.Sprintf("method override %q is not allowed", override))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), origMethodCtxKey, r.Method)
+		r = r.WithContext(ctx)
+		r.Method = override
+		next.ServeHTTP(w, r)
+	})
+}
+
+// OriginalMethodFromContext returns request method overridden by X-HTTP-Method-Override header,
+// or empty string if method is not overridden
+func OriginalMethodFromContext(ctx context.Context) string {
+	return stringFromContext(ctx, origMethodCtxKey)
+}
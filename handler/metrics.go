@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/viper"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	unknownRoute = "unknown"
+)
+
+// instrument defines request metric exported by Prometheus registry and OTel meter provider alike
+type instrument struct {
+	name    string
+	help    string
+	unit    string
+	buckets []float64
+}
+
+var (
+	requestDurationInstrument = instrument{
+		name: "busybox_http_request_duration_seconds",
+		help: "HTTP request duration in seconds",
+		unit: "s",
+	}
+	requestsTotalInstrument = instrument{
+		name: "busybox_http_requests_total",
+		help: "Total number of HTTP requests by status class",
+	}
+	requestBodySizeInstrument = instrument{
+		name: "busybox_request_body_bytes",
+		help: "HTTP request body size in bytes, as declared by Content-Length or read by handler otherwise",
+		unit: "By",
+		// 100B to 1GB
+		buckets: prometheus.ExponentialBuckets(100, 10, 8),
+	}
+	responseSizeInstrument = instrument{
+		name: "busybox_http_response_size_bytes",
+		help: "HTTP response body size in bytes",
+		unit: "By",
+		// 100B to 1GB
+		buckets: prometheus.ExponentialBuckets(100, 10, 8),
+	}
+)
+
+type metrics struct {
+	registry        *prometheus.Registry
+	requestDuration *prometheus.HistogramVec
+	requestsTotal   *prometheus.CounterVec
+	responseSize    *prometheus.HistogramVec
+	requestBodySize *prometheus.HistogramVec
+
+	// otel is set if metrics_exporter pushes request metrics to OTLP collector as well
+	otel *otelMetrics
+}
+
+// durationBuckets parses metric_duration_buckets, or returns prometheus default buckets if not set
+func durationBuckets() ([]float64, error) {
+	values := viper.GetStringSlice("metric_duration_buckets")
+	if len(values) == 0 {
+		return prometheus.DefBuckets, nil
+	}
+
+	buckets := make([]float64, 0, len(values))
+	for _, value := range values {
+		bucket, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric_duration_buckets value '%s': %s", value, err)
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	if !sort.Float64sAreSorted(buckets) {
+		return nil, fmt.Errorf("metric_duration_buckets must be in increasing order")
+	}
+
+	return buckets, nil
+}
+
+// initMetrics registers handler metrics on its own registry served by /metrics
+func (h *Handler) initMetrics() error {
+	buckets, err := durationBuckets()
+	if err != nil {
+		return err
+	}
+
+	durationInstrument := requestDurationInstrument
+	durationInstrument.buckets = buckets
+
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    durationInstrument.name,
+			Help:    durationInstrument.help,
+			Buckets: durationInstrument.buckets,
+		}, []string{"method", "route", "status"}),
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: requestsTotalInstrument.name,
+			Help: requestsTotalInstrument.help,
+		}, []string{"method", "route", "status_class"}),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    responseSizeInstrument.name,
+			Help:    responseSizeInstrument.help,
+			Buckets: responseSizeInstrument.buckets,
+		}, []string{"route"}),
+		requestBodySize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    requestBodySizeInstrument.name,
+			Help:    requestBodySizeInstrument.help,
+			Buckets: requestBodySizeInstrument.buckets,
+		}, []string{"route"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "busybox_inflight_requests",
+			Help: "Number of HTTP requests currently being served",
+		}, func() float64 {
+			return float64(h.inflight.Load())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "busybox_concurrent_requests",
+			Help: "Number of HTTP requests holding max_concurrent_requests limiter slot",
+		}, func() float64 {
+			return float64(len(h.concurrency))
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "busybox_trace_export_errors_total",
+			Help: "Number of failed span exports to trace collector",
+		}, func() float64 {
+			return float64(h.traceExportErrors.Load())
+		}),
+		m.requestDuration,
+		m.requestsTotal,
+		m.responseSize,
+		m.requestBodySize,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "busybox_build_info",
+			Help: "Build details of running busybox, value is always 1",
+			ConstLabels: prometheus.Labels{
+				"version":    AppVersion,
+				"go_version": runtime.Version(),
+				"git_commit": GitCommit,
+			},
+		}, func() float64 {
+			return 1
+		}),
+	)
+
+	if exporterName := viper.GetString("metrics_exporter"); len(exporterName) > 0 && exporterName != metricsExporterPrometheus {
+		if m.otel, err = newOTelMetrics(context.Background(), exporterName, durationInstrument); err != nil {
+			return err
+		}
+		h.OnShutdown(h.shutdownMetrics)
+		h.logger.Debugw("OTel metrics exporter initialized", "exporter", exporterName)
+	}
+
+	h.metrics = m
+	return nil
+}
+
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// observeRequest records request duration, request body and response size, and counts request by status class
+func (m *metrics) observeRequest(r *http.Request, status int, bodySize int64, size int, duration time.Duration) {
+	route := routePattern(r)
+	m.requestDuration.WithLabelValues(r.Method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+	m.requestsTotal.WithLabelValues(r.Method, route, statusClass(status)).Inc()
+	m.responseSize.WithLabelValues(route).Observe(float64(size))
+	m.requestBodySize.WithLabelValues(route).Observe(float64(bodySize))
+
+	if m.otel != nil {
+		m.otel.observeRequest(r.Context(), r.Method, route, status, bodySize, size, duration)
+	}
+}
+
+// statusClass returns status class label, e.g. 2xx
+func statusClass(status int) string {
+	return fmt.Sprintf("%dxx", status/100)
+}
+
+// routePattern returns matched chi route pattern, so metrics are not labeled by raw paths
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); len(pattern) > 0 {
+			return pattern
+		}
+	}
+	return unknownRoute
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"net/http"
+	"time"
+)
+
+// defaultAccessLogLevels are access log levels by response status class
+var defaultAccessLogLevels = map[string]zapcore.Level{
+	"1xx": zapcore.InfoLevel,
+	"2xx": zapcore.InfoLevel,
+	"3xx": zapcore.InfoLevel,
+	"4xx": zapcore.WarnLevel,
+	"5xx": zapcore.ErrorLevel,
+}
+
+// accessLogLevels returns access log levels by status class with access_log_levels applied over defaults,
+// invalid values are reported and defaults are kept for them
+func accessLogLevels() (map[string]zapcore.Level, error) {
+	levels := make(map[string]zapcore.Level, len(defaultAccessLogLevels))
+	for class, level := range defaultAccessLogLevels {
+		levels[class] = level
+	}
+
+	var err error
+	for class, value := range viper.GetStringMapString("access_log_levels") {
+		if _, ok := defaultAccessLogLevels[class]; !ok {
+			err = multierr.Append(err, fmt.Errorf("invalid access_log_levels status class '%s', expected one of 1xx, 2xx, 3xx, 4xx, 5xx", class))
+			continue
+		}
+		level, parseErr := zapcore.ParseLevel(value)
+		if parseErr != nil || level > zapcore.ErrorLevel {
+			err = multierr.Append(err, fmt.Errorf("invalid access_log_levels level '%s' for %s, expected one of debug, info, warn, error", value, class))
+			continue
+		}
+		levels[class] = level
+	}
+	return levels, err
+}
+
+// logFunc returns logger method writing at level
+func logFunc(logger *zap.SugaredLogger, level zapcore.Level) func(msg string, keysAndValues ...any) {
+	switch level {
+	case zapcore.DebugLevel:
+		return logger.Debugw
+	case zapcore.InfoLevel:
+		return logger.Infow
+	case zapcore.WarnLevel:
+		return logger.Warnw
+	default:
+		return logger.Errorw
+	}
+}
+
+// countingBody counts request body bytes read by handler
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+// requestBodySize returns declared Content-Length, or body bytes read by handler if it is unknown
+func requestBodySize(r *http.Request, body *countingBody) int64 {
+	if r.ContentLength >= 0 {
+		return r.ContentLength
+	}
+	return body.n
+}
+
+// accessLog logs every handled request with response status, size and duration,
+// and records request metrics and history.
+// Level depends on status class: info for 1xx-3xx, warn for 4xx and error for 5xx unless access_log_levels is set
+func (h *Handler) accessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		start := time.Now()
+
+		body := &countingBody{ReadCloser: r.Body}
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = body
+		}
+
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			// nothing was written, net/http responds with 200
+			status = http.StatusOK
+		}
+
+		duration := time.Since(start)
+		h.metrics.observeRequest(r, status, requestBodySize(r, body), ww.BytesWritten(), duration)
+		recordSpanResponse(r, status)
+		h.history.add(requestSummary{
+			Time:      start,
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			Status:    status,
+			ClientIP:  ClientIPFromContext(r.Context()),
+			RequestID: RequestIDFromContext(r.Context()),
+			Duration:  duration.String(),
+		})
+
+		level, ok := requestSettings(r).accessLogLevels[statusClass(status)]
+		if !ok {
+			level = zapcore.InfoLevel
+		}
+		logFn := logFunc(h.requestLogger(r), level)
+
+		fields := []any{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"query", r.URL.RawQuery,
+			"client_ip", ClientIPFromContext(r.Context()),
+			"status", status,
+			"duration", duration.String(),
+			"size", ww.BytesWritten(),
+		}
+
+		// correlate log line with request trace, if tracing is enabled
+		if spanCtx := trace.SpanContextFromContext(r.Context()); spanCtx.IsValid() {
+			fields = append(fields,
+				"trace_id", spanCtx.TraceID().String(),
+				"span_id", spanCtx.SpanID().String(),
+			)
+		}
+
+		logFn("Request handled", fields...)
+	})
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccessLogLevels(t *testing.T) {
+	viper.Set("access_log_levels", map[string]string{"2xx": "debug", "4xx": "error"})
+	defer viper.Set("access_log_levels", nil)
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	h := &Handler{logger: zap.New(core).Sugar(), limiter: newRateLimiter(0)}
+	if err := h.initMetrics(); err != nil {
+		t.Fatalf("Unable to init metrics: %s", err)
+	}
+	h.currentSettings.Store(h.loadSettings())
+	h.router = h.newRouter()
+
+	cases := map[string]zapcore.Level{
+		"/status/200": zapcore.DebugLevel,
+		"/status/302": zapcore.InfoLevel,
+		"/status/404": zapcore.ErrorLevel,
+		"/status/503": zapcore.ErrorLevel,
+	}
+	for path, expected := range cases {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+
+		var entries []observer.LoggedEntry
+		for _, entry := range logs.TakeAll() {
+			if entry.Message == "Request handled" {
+				entries = append(entries, entry)
+			}
+		}
+		if len(entries) != 1 {
+			t.Fatalf("Expected single access log entry for %s, got %d", path, len(entries))
+		}
+		if entries[0].Level != expected {
+			t.Errorf("Expected %s to be logged at %s level, got: %s", path, expected, entries[0].Level)
+		}
+	}
+}
+
+func TestAccessLogLevelsInvalid(t *testing.T) {
+	viper.Set("access_log_levels", map[string]string{"2xx": "verbose", "6xx": "info", "4xx": "info"})
+	defer viper.Set("access_log_levels", nil)
+
+	levels, err := accessLogLevels()
+	if err == nil {
+		t.Errorf("Expected invalid level and status class to be reported")
+	}
+	if levels["2xx"] != zapcore.InfoLevel || levels["4xx"] != zapcore.InfoLevel {
+		t.Errorf("Expected defaults to be kept for invalid values, got: %v", levels)
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strings"
+)
+
+// routeMethods are methods checked for Allow header of 405 responses
+var routeMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// writeRouteError responds with error envelope including request path
+func writeRouteError(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	writeError(w, r, status, msg, map[string]any{
+		"path": r.URL.Path,
+	})
+}
+
+// notFound responds to requests not matching any route with JSON error envelope
+func (h *Handler) notFound(w http.ResponseWriter, r *http.Request) {
+	writeRouteError(w, r, http.StatusNotFound, "route not found")
+}
+
+// methodNotAllowed responds to requests matching route of routes with another method with JSON error envelope,
+// methods route can be requested with are listed by Allow header
+func (h *Handler) methodNotAllowed(routes chi.Routes) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.RawPath
+		if len(path) == 0 {
+			path = r.URL.Path
+		}
+
+		// OPTIONS is answered as CORS preflight for any route
+		allowed := []string{http.MethodOptions}
+		for _, method := range routeMethods {
+			if routes.Match(chi.NewRouteContext(), method, path) {
+				allowed = append(allowed, method)
+			}
+		}
+
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeRouteError(w, r, http.StatusMethodNotAllowed, "method "+r.Method+" is not allowed")
+	}
+}
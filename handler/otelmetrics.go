@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"strconv"
+	"time"
+)
+
+const (
+	metricsExporterPrometheus = "prometheus"
+	metricsExporterOtlpGrpc   = "otlp-grpc"
+	metricsExporterOtlpHttp   = "otlp-http"
+
+	defaultMetricsExportInterval = 60 * time.Second
+)
+
+func metricsExportInterval() time.Duration {
+	if interval := viper.GetDuration("metrics_export_interval"); interval > 0 {
+		return interval
+	}
+	return defaultMetricsExportInterval
+}
+
+// otelMetrics pushes request metrics to OTLP collector, instruments mirror Prometheus ones
+type otelMetrics struct {
+	provider        *sdkmetric.MeterProvider
+	requestDuration metric.Float64Histogram
+	requestsTotal   metric.Int64Counter
+	responseSize    metric.Int64Histogram
+	requestBodySize metric.Int64Histogram
+}
+
+// newMetricExporter builds OTLP metric exporter, otlp_endpoint host is shared with trace exporter,
+// while url path is left to exporter default, as trace endpoint path does not accept metrics
+func newMetricExporter(ctx context.Context, name string) (sdkmetric.Exporter, error) {
+	switch name {
+	case metricsExporterOtlpGrpc:
+		var opts []otlpmetricgrpc.Option
+		if host, _, insecure := otlpEndpoint(); len(host) > 0 {
+			opts = append(opts, otlpmetricgrpc.WithEndpoint(host))
+			if insecure {
+				opts = append(opts, otlpmetricgrpc.WithInsecure())
+			}
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	case metricsExporterOtlpHttp:
+		var opts []otlpmetrichttp.Option
+		if host, _, insecure := otlpEndpoint(); len(host) > 0 {
+			opts = append(opts, otlpmetrichttp.WithEndpoint(host))
+			if insecure {
+				opts = append(opts, otlpmetrichttp.WithInsecure())
+			}
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported metrics_exporter '%s'", name)
+	}
+}
+
+// histogramView applies instrument buckets to OTel histogram, so both exporters report the same distribution
+func histogramView(i instrument) sdkmetric.View {
+	return sdkmetric.NewView(
+		sdkmetric.Instrument{Name: i.name},
+		sdkmetric.Stream{Aggregation: sdkmetric.AggregationExplicitBucketHistogram{Boundaries: i.buckets}},
+	)
+}
+
+// newOTelMetrics creates meter provider exporting request metrics every metrics_export_interval
+func newOTelMetrics(ctx context.Context, exporterName string, durationInstrument instrument) (*otelMetrics, error) {
+	exp, err := newMetricExporter(ctx, exporterName)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exp, sdkmetric.WithInterval(metricsExportInterval()))),
+		sdkmetric.WithResource(serviceResource()),
+		sdkmetric.WithView(
+			histogramView(durationInstrument),
+			histogramView(responseSizeInstrument),
+			histogramView(requestBodySizeInstrument),
+		),
+	)
+	meter := provider.Meter("github.com/rovergulf/busybox")
+
+	m := &otelMetrics{provider: provider}
+	if m.requestDuration, err = meter.Float64Histogram(durationInstrument.name,
+		metric.WithDescription(durationInstrument.help), metric.WithUnit(durationInstrument.unit)); err != nil {
+		return nil, err
+	}
+	if m.requestsTotal, err = meter.Int64Counter(requestsTotalInstrument.name,
+		metric.WithDescription(requestsTotalInstrument.help)); err != nil {
+		return nil, err
+	}
+	if m.responseSize, err = meter.Int64Histogram(responseSizeInstrument.name,
+		metric.WithDescription(responseSizeInstrument.help), metric.WithUnit(responseSizeInstrument.unit)); err != nil {
+		return nil, err
+	}
+	if m.requestBodySize, err = meter.Int64Histogram(requestBodySizeInstrument.name,
+		metric.WithDescription(requestBodySizeInstrument.help), metric.WithUnit(requestBodySizeInstrument.unit)); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// observeRequest records request metrics with the same labels Prometheus metrics have
+func (m *otelMetrics) observeRequest(ctx context.Context, method, route string, status int, bodySize int64, size int, duration time.Duration) {
+	m.requestDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(
+		attribute.String("method", method),
+		attribute.String("route", route),
+		attribute.String("status", strconv.Itoa(status)),
+	))
+	m.requestsTotal.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("method", method),
+		attribute.String("route", route),
+		attribute.String("status_class", statusClass(status)),
+	))
+	m.responseSize.Record(ctx, int64(size), metric.WithAttributes(
+		attribute.String("route", route),
+	))
+	m.requestBodySize.Record(ctx, bodySize, metric.WithAttributes(
+		attribute.String("route", route),
+	))
+}
+
+// shutdownMetrics is a shutdown hook flushing pending OTel metrics, it gives up after trace_shutdown_timeout
+// the same way tracer does, so unavailable collector does not block shutdown
+func (h *Handler) shutdownMetrics(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, traceShutdownTimeout())
+	defer cancel()
+
+	if err := h.metrics.otel.provider.Shutdown(ctx); err != nil {
+		return fmt.Errorf("unable to flush metrics: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOTelMetricsExport(t *testing.T) {
+	var exported atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/v1/metrics" {
+			exported.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	settings := map[string]any{
+		"metrics_exporter": metricsExporterOtlpHttp,
+		"otlp_endpoint":    collector.URL,
+	}
+	for key, value := range settings {
+		viper.Set(key, value)
+	}
+	defer func() {
+		for key := range settings {
+			viper.Set(key, nil)
+		}
+	}()
+
+	h := &Handler{logger: zap.NewNop().Sugar(), limiter: newRateLimiter(0)}
+	if err := h.initMetrics(); err != nil {
+		t.Fatalf("Unable to init metrics: %s", err)
+	}
+	if h.metrics.otel == nil {
+		t.Fatalf("OTel metrics must be initialized for %s exporter", metricsExporterOtlpHttp)
+	}
+	h.currentSettings.Store(h.loadSettings())
+	h.router = h.newRouter()
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/status/204", nil))
+
+	// shutdown hook flushes recorded metrics
+	if err := h.runShutdownHooks(context.Background()); err != nil {
+		t.Errorf("Unable to flush metrics: %s", err)
+	}
+	if exported.Load() == 0 {
+		t.Errorf("Expected request metrics to be pushed to OTLP collector")
+	}
+}
+
+func TestOTelMetricsDisabledByDefault(t *testing.T) {
+	h := &Handler{logger: zap.NewNop().Sugar()}
+	if err := h.initMetrics(); err != nil {
+		t.Fatalf("Unable to init metrics: %s", err)
+	}
+	if h.metrics.otel != nil {
+		t.Errorf("OTel metrics must not be initialized unless metrics_exporter is set")
+	}
+}
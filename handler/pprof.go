@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/pprof"
+)
+
+// pprofHandler serves net/http/pprof, it must be mounted at /debug/pprof
+// as pprof.Index resolves profile names from the full request path
+func pprofHandler() http.Handler {
+	r := chi.NewRouter()
+	r.Use(middleware.NoCache)
+
+	r.HandleFunc("/", pprof.Index)
+	r.HandleFunc("/*", pprof.Index)
+	r.HandleFunc("/cmdline", pprof.Cmdline)
+	r.HandleFunc("/profile", pprof.Profile)
+	r.HandleFunc("/symbol", pprof.Symbol)
+	r.HandleFunc("/trace", pprof.Trace)
+
+	return r
+}
+
+// servePprof starts private pprof listener at pprof_listen_addr, if profiling is enabled and address is set
+func (h *Handler) servePprof() error {
+	addr := viper.GetString("pprof_listen_addr")
+	if !viper.GetBool("enable_profiling") || len(addr) == 0 {
+		return nil
+	}
+
+	r := chi.NewRouter()
+	r.Use(h.authenticate)
+	r.Mount("/debug/pprof", pprofHandler())
+	return h.serveAux("pprof", addr, r)
+}
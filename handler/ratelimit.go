@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"container/list"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRateLimitMaxClients = 10000
+)
+
+type tokenBucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter keeps token buckets per client in a bounded LRU,
+// so the least recently seen clients are evicted first
+type rateLimiter struct {
+	mu         sync.Mutex
+	maxClients int
+	buckets    map[string]*list.Element
+	lru        *list.List
+}
+
+func newRateLimiter(maxClients int) *rateLimiter {
+	if maxClients <= 0 {
+		maxClients = defaultRateLimitMaxClients
+	}
+
+	return &rateLimiter{
+		maxClients: maxClients,
+		buckets:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// allow takes a token from the client bucket refilled with rps rate up to burst size,
+// if there is no token available it returns time to wait until the next one
+func (l *rateLimiter) allow(key string, rps float64, burst int, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var bucket *tokenBucket
+	if el, ok := l.buckets[key]; ok {
+		l.lru.MoveToFront(el)
+		bucket = el.Value.(*tokenBucket)
+		bucket.tokens = math.Min(float64(burst), bucket.tokens+now.Sub(bucket.last).Seconds()*rps)
+		bucket.last = now
+	} else {
+		bucket = &tokenBucket{key: key, tokens: float64(burst), last: now}
+		l.buckets[key] = l.lru.PushFront(bucket)
+
+		if l.lru.Len() > l.maxClients {
+			oldest := l.lru.Back()
+			l.lru.Remove(oldest)
+			delete(l.buckets, oldest.Value.(*tokenBucket).key)
+		}
+	}
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	return false, time.Duration((1 - bucket.tokens) / rps * float64(time.Second))
+}
+
+// rateLimit rejects requests exceeding rate_limit_rps per resolved client IP with 429,
+// rate limiting is disabled unless rate_limit_rps is set
+func (h *Handler) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := requestSettings(r)
+		if cfg.rateLimitRPS <= 0 || h.limiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		allowed, retryAfter := h.limiter.allow(ClientIPFromContext(r.Context()), cfg.rateLimitRPS, cfg.rateLimitBurst, time.Now())
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			writeError(w, r, http.StatusTooManyRequests, "rate limit exceeded")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	limiter := newRateLimiter(10)
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if allowed, _ := limiter.allow("client", 1, 2, now); !allowed {
+			t.Fatalf("Request %d within burst must be allowed", i)
+		}
+	}
+
+	allowed, retryAfter := limiter.allow("client", 1, 2, now)
+	if allowed {
+		t.Fatalf("Request exceeding burst must be rejected")
+	}
+	if retryAfter <= 0 || retryAfter > time.Second {
+		t.Errorf("Invalid retry after duration: %s", retryAfter)
+	}
+
+	if allowed, _ := limiter.allow("client", 1, 2, now.Add(time.Second)); !allowed {
+		t.Errorf("Request must be allowed after bucket is refilled")
+	}
+}
+
+func TestRateLimiterEviction(t *testing.T) {
+	limiter := newRateLimiter(2)
+	now := time.Now()
+
+	for _, key := range []string{"first", "second", "third"} {
+		limiter.allow(key, 1, 1, now)
+	}
+
+	if limiter.lru.Len() != 2 {
+		t.Errorf("Limiter must keep at most 2 clients, got: %d", limiter.lru.Len())
+	}
+
+	if _, ok := limiter.buckets["first"]; ok {
+		t.Errorf("Least recently seen client must be evicted")
+	}
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/viper"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultMaxRedirects = 20
+)
+
+func maxRedirects() int {
+	if limit := viper.GetInt("max_redirects"); limit > 0 {
+		return limit
+	}
+	return defaultMaxRedirects
+}
+
+// redirectHandler redirects /redirect/{n} to /redirect/{n-1} until /redirect/0 responds with request echo,
+// n over max_redirects is rejected. URLs are absolute if ?absolute=true is passed
+func (h *Handler) redirectHandler(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(chi.URLParam(r, "n"))
+	if err != nil || n < 0 {
+		writeError(w, r, http.StatusBadRequest, "invalid redirects count")
+		return
+	}
+
+	if limit := requestSettings(r).maxRedirects; n > limit {
+		writeError(w, r, http.StatusBadRequest, fmt.Sprintf("redirects count exceeds max_redirects limit of %d", limit), map[string]any{
+			"limit": limit,
+		})
+		return
+	}
+
+	if n == 0 {
+		if results, ok := h.echoResults(w, r); ok {
+			writeResponse(w, r, results)
+		}
+		return
+	}
+
+	location := h.routeURL(fmt.Sprintf("/redirect/%d", n-1))
+	if absolute, _ := strconv.ParseBool(r.URL.Query().Get("absolute")); absolute {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		location = fmt.Sprintf("%s://%s%s?absolute=true", scheme, r.Host, location)
+	}
+
+	http.Redirect(w, r, location, http.StatusFound)
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+const (
+	requestIDHeader = "X-Request-Id"
+
+	maxRequestIDLength = 128
+)
+
+// newUUID returns random version 4 UUID
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// validRequestID accepts printable ASCII IDs of reasonable length only,
+// so client supplied value can be safely written to logs
+func validRequestID(id string) bool {
+	if len(id) == 0 || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// requestID middleware takes request ID from X-Request-Id header or generates a new one,
+// stores it in request context and span, and sets it to response header
+func (h *Handler) requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			var err error
+			if id, err = newUUID(); err != nil {
+				h.logger.Errorw("Unable to generate request ID", "err", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		w.Header().Set(requestIDHeader, id)
+		trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("request_id", id))
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDCtxKey, id)))
+	})
+}
+
+// requestLogger returns logger annotated with request ID
+func (h *Handler) requestLogger(r *http.Request) *zap.SugaredLogger {
+	if id := RequestIDFromContext(r.Context()); len(id) > 0 {
+		return h.logger.With("request_id", id)
+	}
+	return h.logger
+}
@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	defaultJSONIndent = "  "
+)
+
+// responseEncoder marshals response value into specific content type,
+// marshalIndent is used for pretty output if encoder supports it
+type responseEncoder struct {
+	contentType   string
+	marshal       func(v any) ([]byte, error)
+	marshalIndent func(v any, indent string) ([]byte, error)
+}
+
+var (
+	jsonEncoder = responseEncoder{
+		contentType: "application/json; charset=utf-8",
+		marshal:     json.Marshal,
+		marshalIndent: func(v any, indent string) ([]byte, error) {
+			return json.MarshalIndent(v, "", indent)
+		},
+	}
+	xmlEncoder = responseEncoder{
+		contentType: "application/xml; charset=utf-8",
+		marshal:     marshalXML,
+	}
+	yamlEncoder = responseEncoder{
+		contentType: "application/yaml; charset=utf-8",
+		marshal:     marshalYAML,
+	}
+)
+
+var responseEncoders = map[string]responseEncoder{
+	"*/*":                jsonEncoder,
+	"application/*":      jsonEncoder,
+	"application/json":   jsonEncoder,
+	"application/xml":    xmlEncoder,
+	"text/xml":           xmlEncoder,
+	"application/yaml":   yamlEncoder,
+	"application/x-yaml": yamlEncoder,
+	"text/yaml":          yamlEncoder,
+}
+
+// negotiateEncoder picks response encoder by request Accept header preferring higher quality values,
+// JSON is used if Accept header is absent or has no supported media types
+func negotiateEncoder(r *http.Request) responseEncoder {
+	accept := r.Header.Get("Accept")
+	if len(accept) == 0 {
+		return jsonEncoder
+	}
+
+	type acceptRange struct {
+		mediaType string
+		quality   float64
+	}
+
+	var ranges []acceptRange
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(value)
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		ranges = append(ranges, acceptRange{mediaType: mediaType, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	for _, ar := range ranges {
+		if encoder, ok := responseEncoders[ar.mediaType]; ok && ar.quality > 0 {
+			return encoder
+		}
+	}
+
+	return jsonEncoder
+}
+
+// toGeneric converts value into generic maps and slices, so all encoders follow the same json tags
+func toGeneric(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+
+	return generic, nil
+}
+
+func marshalYAML(v any) ([]byte, error) {
+	generic, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(generic)
+}
+
+func marshalXML(v any) ([]byte, error) {
+	generic, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+
+	encoder := xml.NewEncoder(&sb)
+	if err := encodeXMLElement(encoder, "response", generic); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+
+	return []byte(sb.String()), nil
+}
+
+// encodeXMLElement writes generic value as xml element,
+// map keys become child elements and slice values are written as repeated <item> elements
+func encodeXMLElement(encoder *xml.Encoder, name string, v any) error {
+	start := xml.StartElement{Name: xml.Name{Local: xmlElementName(name)}}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch value := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if err := encodeXMLElement(encoder, key, value[key]); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range value {
+			if err := encodeXMLElement(encoder, "item", item); err != nil {
+				return err
+			}
+		}
+	case string:
+		if err := encoder.EncodeToken(xml.CharData(value)); err != nil {
+			return err
+		}
+	case float64:
+		if err := encoder.EncodeToken(xml.CharData(strconv.FormatFloat(value, 'f', -1, 64))); err != nil {
+			return err
+		}
+	case bool:
+		if err := encoder.EncodeToken(xml.CharData(strconv.FormatBool(value))); err != nil {
+			return err
+		}
+	}
+
+	return encoder.EncodeToken(start.End())
+}
+
+// xmlElementName replaces characters not allowed in xml names, e.g. from header names or query keys
+func xmlElementName(name string) string {
+	name = strings.Map(func(c rune) rune {
+		if c == '_' || c == '-' || c == '.' || unicode.IsLetter(c) || unicode.IsDigit(c) {
+			return c
+		}
+		return '_'
+	}, name)
+
+	if len(name) == 0 || !(name[0] == '_' || unicode.IsLetter(rune(name[0]))) {
+		return "_" + name
+	}
+
+	return name
+}
+
+// prettyRequested reports whether client asked for indented output
+// with pretty query parameter or X-Pretty header
+func prettyRequested(r *http.Request) bool {
+	query := r.URL.Query()
+	if query.Has("pretty") {
+		value := query.Get("pretty")
+		pretty, err := strconv.ParseBool(value)
+		return len(value) == 0 || (err == nil && pretty)
+	}
+
+	pretty, _ := strconv.ParseBool(r.Header.Get("X-Pretty"))
+	return pretty
+}
+
+func jsonIndent() string {
+	if viper.IsSet("json_indent") {
+		return viper.GetString("json_indent")
+	}
+	return defaultJSONIndent
+}
+
+func writeResponse(w http.ResponseWriter, r *http.Request, v any) {
+	writeStatusResponse(w, r, http.StatusOK, v)
+}
+
+// marshalResponse encodes value by format negotiated with client and returns its content type
+func marshalResponse(r *http.Request, v any) (string, []byte, error) {
+	encoder := negotiateEncoder(r)
+
+	var response []byte
+	var err error
+	if encoder.marshalIndent != nil && prettyRequested(r) {
+		response, err = encoder.marshalIndent(v, requestSettings(r).jsonIndent)
+	} else {
+		response, err = encoder.marshal(v)
+	}
+
+	return encoder.contentType, response, err
+}
+
+// writeMarshalError responds with plain text error, as response value can't be encoded
+func writeMarshalError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusInternalServerError)
+	w.Write([]byte("Cannot marshal response: " + err.Error()))
+}
+
+// writeStatusResponse writes value with given status encoded by format negotiated with client
+func writeStatusResponse(w http.ResponseWriter, r *http.Request, status int, v any) {
+	contentType, response, err := marshalResponse(r, v)
+	if err != nil {
+		writeMarshalError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	w.Write(response)
+}
+
+// writeError responds with {"error": {"status": status, "message": msg}} envelope,
+// fields add error details to the envelope, e.g. exceeded limit
+func writeError(w http.ResponseWriter, r *http.Request, status int, msg string, fields ...map[string]any) {
+	envelope := map[string]any{
+		"status":  status,
+		"message": msg,
+	}
+	for _, f := range fields {
+		for key, value := range f {
+			envelope[key] = value
+		}
+	}
+
+	writeStatusResponse(w, r, status, map[string]any{
+		"error": envelope,
+	})
+}
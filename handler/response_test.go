@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteResponseMarshalError(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/debug", nil)
+	w := httptest.NewRecorder()
+
+	writeResponse(w, r, map[string]any{
+		"unsupported": make(chan int),
+	})
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Marshal error must result in 500, got: %d", w.Code)
+	}
+
+	if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain") {
+		t.Errorf("Invalid error content type: %s", contentType)
+	}
+}
+
+func TestWriteErrorFields(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/debug", nil)
+	w := httptest.NewRecorder()
+
+	writeError(w, r, http.StatusRequestEntityTooLarge, "too large", map[string]any{"limit": 1024})
+
+	var result struct {
+		Error map[string]any `json:"error"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Unable to unmarshal error response: %s", err)
+	}
+
+	expected := map[string]any{"status": float64(413), "message": "too large", "limit": float64(1024)}
+	if !reflect.DeepEqual(result.Error, expected) {
+		t.Errorf("Expected error envelope %v, got: %v", expected, result.Error)
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/spf13/viper"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultLogResponseBodyMaxBytes = 4096
+)
+
+func logResponseBodyMaxBytes() int {
+	if limit := viper.GetInt("log_response_body_max_bytes"); limit > 0 {
+		return limit
+	}
+	return defaultLogResponseBodyMaxBytes
+}
+
+// redactBodyFields masks values of JSON object fields named like secrets, e.g. password or token,
+// echo output has redact_headers and redact_cookies masked already, but echoed request body is not
+func redactBodyFields(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for key, item := range value {
+			if secretConfigKey(strings.ToLower(key)) {
+				value[key] = redactedValue
+				continue
+			}
+			value[key] = redactBodyFields(item)
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = redactBodyFields(item)
+		}
+	}
+	return v
+}
+
+// loggedResponseBody returns response body as it is logged: JSON fields named like secrets are masked,
+// raw request body echoed along with decoding error is dropped, and result is truncated to maxBytes
+func loggedResponseBody(data []byte, contentType string, maxBytes int) string {
+	if strings.HasPrefix(contentType, "application/json") {
+		var generic any
+		if err := json.Unmarshal(data, &generic); err == nil {
+			if fields, ok := generic.(map[string]any); ok && fields["body_raw"] != nil {
+				fields["body_raw"] = redactedValue
+			}
+			if redacted, err := json.Marshal(redactBodyFields(generic)); err == nil {
+				data = redacted
+			}
+		}
+	}
+
+	if len(data) > maxBytes {
+		return string(data[:maxBytes]) + "..."
+	}
+	return string(data)
+}
+
+// logResponseBody logs response bodies of /debug routes at debug level if log_response_bodies is set
+func (h *Handler) logResponseBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := requestSettings(r)
+		logger := h.requestLogger(r)
+		if !cfg.logResponseBodies || !logger.Desugar().Core().Enabled(zapcore.DebugLevel) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var body bytes.Buffer
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		ww.Tee(&body)
+
+		next.ServeHTTP(ww, r)
+
+		logger.Debugw("Response body",
+			"path", r.URL.Path,
+			"status", ww.Status(),
+			"size", ww.BytesWritten(),
+			"body", loggedResponseBody(body.Bytes(), w.Header().Get("Content-Type"), cfg.logResponseBodyMaxBytes),
+		)
+	})
+}
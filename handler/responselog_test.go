@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoggedResponseBody(t *testing.T) {
+	data := []byte(`{"method":"POST","body":{"user":"admin","password":"hunter2","nested":[{"api_token":"abc"}]},"body_raw":"password=hunter2"}`)
+
+	logged := loggedResponseBody(data, "application/json; charset=utf-8", 4096)
+	for _, secret := range []string{"hunter2", "abc"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("Secret '%s' must be redacted, got: %s", secret, logged)
+		}
+	}
+	if !strings.Contains(logged, `"user":"admin"`) {
+		t.Errorf("Non-secret fields must be kept, got: %s", logged)
+	}
+
+	logged = loggedResponseBody([]byte(strings.Repeat("x", 100)), "text/plain", 10)
+	if logged != strings.Repeat("x", 10)+"..." {
+		t.Errorf("Body must be truncated to 10 bytes, got: %s", logged)
+	}
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// listenerKeys are restart required keys applied by main listener restart,
+// admin and pprof listeners are not restarted
+var listenerKeys = []string{
+	"listen_addr",
+	"listen_network",
+	"tls_cert",
+	"tls_key",
+	"tls_min_version",
+	"tls_client_auth",
+	"tls_client_ca_file",
+	"read_header_timeout",
+	"read_timeout",
+	"write_timeout",
+	"idle_timeout",
+	"max_header_bytes",
+}
+
+// listenerChanged tells whether any of main listener settings has changed since server has started
+func (h *Handler) listenerChanged() bool {
+	for _, key := range h.changedRestartKeys() {
+		if containsFold(listenerKeys, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// restartListener starts new main servers with current configuration and only then drains the old ones,
+// so no connection is refused in between. Sockets of unchanged addresses are handed over to new servers,
+// so connections queued on them are not dropped once old servers are shut down.
+// New servers failures stop the process the same way failures of servers started by Run do
+func (h *Handler) restartListener() error {
+	servers, useTLS, err := h.newServers()
+	if err != nil {
+		return err
+	}
+	for _, server := range servers {
+		if strings.HasPrefix(server.Addr, unixAddrPrefix) {
+			return errors.New("listener restart is not supported for Unix domain sockets")
+		}
+	}
+
+	h.serverMu.Lock()
+	current := h.listeners
+	h.serverMu.Unlock()
+
+	shared, listeners, err := listenAll(servers, current)
+	if err != nil {
+		return err
+	}
+
+	// shutdown clears ready under serverMu before it takes servers to shut down,
+	// so either new servers are swapped in before and shut down with the rest, or they are never served
+	h.serverMu.Lock()
+	if !h.ready.Load() {
+		h.serverMu.Unlock()
+		closeUnusedListeners(shared, current)
+		return errors.New("server is shutting down")
+	}
+	old := h.servers
+	h.servers = servers
+	h.listenAddrs = boundAddrs(listeners)
+	h.listeners = shared
+	for _, key := range listenerKeys {
+		h.startupValues[key] = restartValues()[key]
+	}
+	h.serverMu.Unlock()
+
+	h.serveListeners(servers, listeners, useTLS)
+	for _, ln := range listeners {
+		h.logger.Infow("HTTP server listener restarted", "listen_addr", boundAddr(ln), "tls", useTLS)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
+	defer cancel()
+
+	if err := shutdownServers(ctx, old); err != nil {
+		h.logger.Warnw("Unable to gracefully drain previous HTTP server", "err", err)
+	}
+	closeUnusedListeners(current, shared)
+	return nil
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"github.com/spf13/viper"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRestartListener(t *testing.T) {
+	values := map[string]any{
+		"listen_addr":   "127.0.0.1:8086",
+		"reuse_port":    true,
+		"write_timeout": 90 * time.Second,
+	}
+	for key, value := range values {
+		viper.Set(key, value)
+	}
+	defer func() {
+		for key := range values {
+			viper.Set(key, nil)
+		}
+	}()
+
+	h := new(Handler)
+	go h.Run()
+	defer h.GracefulShutdown("test")
+
+	for i := 0; ; i++ {
+		if res, err := http.Get("http://127.0.0.1:8086/readyz"); err == nil {
+			res.Body.Close()
+			if res.StatusCode == http.StatusOK {
+				break
+			}
+		}
+		if i == 50 {
+			t.Fatalf("Server is not ready")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	old := h.currentServers()[0]
+
+	// requests sent during restart must not be refused, every request dials new connection
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	var failed atomic.Int32
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			res, err := client.Get("http://127.0.0.1:8086/health")
+			if err != nil {
+				failed.Add(1)
+				continue
+			}
+			res.Body.Close()
+		}
+	}()
+
+	viper.Set("write_timeout", 120*time.Second)
+	h.reloadConfig()
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+	<-done
+
+	server := h.currentServers()[0]
+	if server == old {
+		t.Fatalf("Expected server to be replaced on listener settings change")
+	}
+	if server.WriteTimeout != 120*time.Second {
+		t.Errorf("Expected new write_timeout to be applied, got: %s", server.WriteTimeout)
+	}
+	if n := failed.Load(); n > 0 {
+		t.Errorf("Expected no request to fail during restart, failed: %d", n)
+	}
+	res, err := client.Get("http://127.0.0.1:8086/health")
+	if err != nil {
+		t.Fatalf("Expected restarted listener to serve requests, got: %s", err)
+	}
+	res.Body.Close()
+	if h.listenerChanged() {
+		t.Errorf("Restarted listener settings must not be reported as changed")
+	}
+}
+
+func TestRestartListenerDuringShutdown(t *testing.T) {
+	viper.Set("listen_addr", "127.0.0.1:8087")
+	defer viper.Set("listen_addr", nil)
+
+	old := &http.Server{Addr: "127.0.0.1:8087"}
+	h := &Handler{servers: []*http.Server{old}}
+	if err := h.restartListener(); err == nil {
+		t.Fatalf("Expected listener restart to be refused once shutdown has started")
+	}
+	if servers := h.currentServers(); len(servers) != 1 || servers[0] != old {
+		t.Errorf("Expected servers not to be replaced during shutdown")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:8087")
+	if err != nil {
+		t.Fatalf("Expected new listener to be closed, got: %s", err)
+	}
+	ln.Close()
+}
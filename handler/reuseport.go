@@ -0,0 +1,20 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package handler
+
+import (
+	"golang.org/x/sys/unix"
+	"syscall"
+)
+
+// reusePortControl sets SO_REUSEPORT, so another listener can be bound to the same address
+// while this one is still open, e.g. by restarted server or new process
+func reusePortControl(network, address string, c syscall.RawConn) error {
+	var sockErr error
+	if err := c.Control(func(fd uintptr) {
+		sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+	}); err != nil {
+		return err
+	}
+	return sockErr
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/viper"
+	"net/http"
+	"strings"
+)
+
+// disabledRoutes lists route paths which are not mounted, every entry disables the route itself
+// and all routes below it, e.g. /debug disables /debug/loglevel and /delay disables /delay/{duration}
+type disabledRoutes []string
+
+func (d disabledRoutes) enabled(path string) bool {
+	for _, disabled := range d {
+		disabled = "/" + strings.Trim(disabled, "/")
+		if path == disabled || strings.HasPrefix(path, disabled+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// basePath returns normalized base_path all routes are mounted at, e.g. /tools/busybox,
+// or empty string if routes are served from the root
+func basePath() string {
+	path := strings.Trim(strings.TrimSpace(viper.GetString("base_path")), "/")
+	if len(path) == 0 {
+		return ""
+	}
+	return "/" + path
+}
+
+// routeURL returns path of the route prefixed with base path routes are mounted at
+func (h *Handler) routeURL(path string) string {
+	return h.basePath + path
+}
+
+func (h *Handler) newRouter() chi.Router {
+	r := chi.NewRouter()
+	r.Use(h.requestID)
+	r.Use(h.methodOverride)
+	r.Use(h.accessLog)
+	r.Use(h.serverTiming)
+	r.Use(h.limitHeaders)
+	r.Use(h.responseHeaders)
+	r.Use(h.compress)
+	// mounted routers inherit JSON 404 and 405 responses
+	r.NotFound(h.notFound)
+	r.MethodNotAllowed(h.methodNotAllowed(r))
+
+	if len(h.basePath) == 0 {
+		h.mountRoutes(r)
+		return r
+	}
+
+	// handlers see full request path, chi matches routes with base path stripped
+	routes := chi.NewRouter()
+	h.mountRoutes(routes)
+	r.Mount(h.basePath, routes)
+	return r
+}
+
+// mountRoutes registers main listener routes on r
+func (h *Handler) mountRoutes(r chi.Router) {
+	disabled := disabledRoutes(viper.GetStringSlice("disabled_routes"))
+
+	// health checks are never authenticated, rate or concurrency limited
+	if disabled.enabled("/health") {
+		r.Get("/health", h.healthCheck)
+	}
+	if disabled.enabled("/livez") {
+		r.Get("/livez", h.healthCheck)
+	}
+	if disabled.enabled("/readyz") && !adminEnabled() {
+		r.Get("/readyz", h.readinessCheck)
+	}
+
+	r.Group(func(lr chi.Router) {
+		lr.Use(h.limitConcurrency)
+		lr.Use(h.rateLimit)
+		lr.Use(h.requestTimeout)
+
+		lr.Group(func(ar chi.Router) {
+			ar.Use(h.authenticate)
+
+			// Go profiler and Prometheus metrics, unless they are served by admin listener
+			if !adminEnabled() {
+				h.mountAdminRoutes(ar, disabled)
+			}
+
+			// effective configuration
+			if disabled.enabled("/config") {
+				ar.Get("/config", h.configHandler)
+			}
+
+			ar.Route("/debug", func(cr chi.Router) {
+				cr.Use(h.logResponseBody)
+
+				// echo every allowed method, so any verb gets the same reflection,
+				// OPTIONS is answered as CORS preflight before routing
+				if disabled.enabled("/debug") {
+					for _, method := range allowedMethods {
+						if method != http.MethodOptions {
+							cr.Method(method, "/", http.HandlerFunc(h.mainHandler))
+						}
+					}
+				}
+
+				// recently handled requests
+				if disabled.enabled("/debug/requests") {
+					cr.Get("/requests", h.requestHistoryHandler)
+				}
+
+				// allowlisted process environment
+				if disabled.enabled("/debug/env") {
+					cr.Get("/env", h.envHandler)
+				}
+
+				// zap level handler reads level with GET and sets it with PUT
+				if disabled.enabled("/debug/loglevel") {
+					cr.Method(http.MethodGet, "/loglevel", h.logLevel)
+					cr.Method(http.MethodPut, "/loglevel", h.logLevel)
+				}
+			})
+		})
+
+		// service routes
+		if disabled.enabled("/delay/{duration}") {
+			lr.Get("/delay/{duration}", h.delayHandler)
+			lr.Post("/delay/{duration}", h.delayHandler)
+		}
+		if disabled.enabled("/status/{codes}") {
+			lr.HandleFunc("/status/{codes}", h.statusHandler)
+		}
+		if disabled.enabled("/stream/{n}") {
+			lr.Get("/stream/{n}", h.streamHandler)
+		}
+		if disabled.enabled("/sse") {
+			lr.Get("/sse", h.sseHandler)
+		}
+		if disabled.enabled("/headers") {
+			lr.Get("/headers", h.headersHandler)
+		}
+		if disabled.enabled("/ip") {
+			lr.Get("/ip", h.ipHandler)
+		}
+		if disabled.enabled("/uuid") {
+			lr.Get("/uuid", h.uuidHandler)
+		}
+		if disabled.enabled("/base64/{value}") {
+			lr.Get("/base64/{value}", h.base64Handler)
+		}
+		if disabled.enabled("/cookies") {
+			lr.Get("/cookies", h.cookiesHandler)
+		}
+		if disabled.enabled("/cookies/set") {
+			lr.Get("/cookies/set", h.setCookiesHandler)
+		}
+		if disabled.enabled("/cookies/delete") {
+			lr.Get("/cookies/delete", h.deleteCookiesHandler)
+		}
+		if disabled.enabled("/redirect/{n}") {
+			lr.Get("/redirect/{n}", h.redirectHandler)
+		}
+		if disabled.enabled("/bytes/{n}") {
+			lr.Get("/bytes/{n}", h.bytesHandler)
+		}
+		if disabled.enabled("/drip") {
+			lr.Get("/drip", h.dripHandler)
+		}
+		if disabled.enabled("/gzip") {
+			lr.Get("/gzip", h.gzipHandler)
+		}
+		if disabled.enabled("/deflate") {
+			lr.Get("/deflate", h.deflateHandler)
+		}
+		if disabled.enabled("/cache") {
+			lr.Get("/cache", h.cacheHandler)
+			lr.Get("/cache/{seconds}", h.cacheHandler)
+		}
+		if disabled.enabled("/basic-auth") {
+			lr.Get("/basic-auth/{user}/{passwd}", h.basicAuthHandler)
+		}
+		if disabled.enabled("/bearer") {
+			lr.Get("/bearer", h.bearerHandler)
+		}
+		if disabled.enabled("/echo") {
+			lr.HandleFunc("/echo", h.echoHandler)
+		}
+		if disabled.enabled("/anything") {
+			lr.HandleFunc("/anything", h.anythingHandler)
+			lr.HandleFunc("/anything/*", h.anythingHandler)
+		}
+	})
+}
+
+// newAdminRouter builds admin_listen_addr listener router serving metrics, profiler and readiness check
+func (h *Handler) newAdminRouter() chi.Router {
+	disabled := disabledRoutes(viper.GetStringSlice("disabled_routes"))
+
+	r := chi.NewRouter()
+	r.Use(h.requestID)
+	r.Use(h.accessLog)
+	r.NotFound(h.notFound)
+	r.MethodNotAllowed(h.methodNotAllowed(r))
+
+	if disabled.enabled("/readyz") {
+		r.Get("/readyz", h.readinessCheck)
+	}
+
+	r.Group(func(ar chi.Router) {
+		ar.Use(h.authenticate)
+		h.mountAdminRoutes(ar, disabled)
+	})
+
+	return r
+}
+
+// mountAdminRoutes mounts Go profiler, unless it is served by pprof_listen_addr listener, and Prometheus metrics
+func (h *Handler) mountAdminRoutes(r chi.Router, disabled disabledRoutes) {
+	if viper.GetBool("enable_profiling") && len(viper.GetString("pprof_listen_addr")) == 0 &&
+		disabled.enabled("/debug/pprof") {
+		r.Mount("/debug/pprof", pprofHandler())
+	}
+
+	if disabled.enabled("/metrics") {
+		r.Mount("/metrics", h.metrics.handler())
+	}
+}
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"testing"
+)
+
+func TestDisabledRoutes(t *testing.T) {
+	disabled := disabledRoutes{"/debug/pprof", "metrics/", "/delay"}
+
+	cases := []struct {
+		path     string
+		expected bool
+	}{
+		{path: "/debug/pprof", expected: false},
+		{path: "/debug/pprof/heap", expected: false},
+		{path: "/debug", expected: true},
+		{path: "/metrics", expected: false},
+		{path: "/delay/{duration}", expected: false},
+		{path: "/delayed", expected: true},
+		{path: "/health", expected: true},
+	}
+
+	for _, c := range cases {
+		if enabled := disabled.enabled(c.path); enabled != c.expected {
+			t.Errorf("Expected %s enabled to be %t, got %t", c.path, c.expected, enabled)
+		}
+	}
+}
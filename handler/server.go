@@ -2,35 +2,45 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/viper"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/jaeger"
-	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/propagation"
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"net"
 	"net/http"
-	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-var AppVersion string
+// build details set with -ldflags "-X github.com/rovergulf/busybox/handler.AppVersion=..."
+var (
+	AppVersion string
+	GitCommit  string
+	BuildDate  string
+)
 
 var (
 	runDate = time.Now()
 )
 
 const (
+	// DefaultListenAddr is used if listen_addr is not configured
+	DefaultListenAddr = ":8081"
+
 	headersSep = ", "
+
+	defaultShutdownTimeout = 15 * time.Second
 )
 
 var allowedHeaders = []string{
@@ -57,119 +67,357 @@ var allowedMethods = []string{
 }
 
 type Handler struct {
-	logger *zap.SugaredLogger
-	tracer *tracesdk.TracerProvider
-	router chi.Router
+	logger   *zap.SugaredLogger
+	logLevel zap.AtomicLevel
+	tracer   *tracesdk.TracerProvider
+	metrics  *metrics
+	limiter  *rateLimiter
+	history  *requestHistory
+	// basePath is base_path main listener routes are mounted at
+	basePath string
+	// concurrency is max_concurrent_requests semaphore, nil if concurrency is not limited
+	concurrency chan struct{}
+
+	// currentSettings is a runtime settings snapshot replaced on config reload
+	currentSettings atomic.Pointer[settings]
+	router          chi.Router
+	// servers are main HTTP servers, one per listen_addr address, listenAddrs are addresses they are bound to,
+	// listeners are their sockets by listenerKey and startupValues are restart required values they are started with.
+	// All of them are guarded by serverMu as listener restart replaces them
+	servers       []*http.Server
+	listenAddrs   []string
+	listeners     map[string]*sharedListener
+	startupValues map[string]any
+	serverMu      sync.Mutex
+	// auxServers are admin and pprof listeners, shut down along with main server
+	auxServers []*http.Server
+
+	// ready is set once Run has finished initialization
+	ready atomic.Bool
+
+	// inflight is a number of requests currently being served
+	inflight atomic.Int64
+	// traceExportErrors is a number of failed span exports
+	traceExportErrors atomic.Int64
+
+	checksMu        sync.RWMutex
+	readinessChecks map[string]ReadinessCheck
+
+	hooksMu       sync.Mutex
+	shutdownHooks []ShutdownHook
+
+	// serveErrs receives the first failure of main servers, both started by Run and by listener restart
+	serveErrs chan error
+	// stopped is closed once GracefulShutdown has drained the server
+	stopped      chan struct{}
+	shutdownOnce sync.Once
 }
 
-func (h *Handler) initLogger() error {
-	cfg := zap.NewDevelopmentConfig()
-	cfg.Development = viper.GetString("env") != "main"
-	cfg.DisableStacktrace = !viper.GetBool("log_stacktrace")
+func (h *Handler) Run() error {
+	if err := h.validateConfig(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	h.initLogger()
+	for _, warning := range multierr.Errors(h.configWarnings()) {
+		h.logger.Warnw("Invalid configuration value, default is used instead", "err", warning)
+	}
+
+	// OTel SDK errors are logged whichever of tracer and metrics exporters is used
+	otel.SetErrorHandler(h.otelErrorHandler())
+
+	if err := h.initTracer(); err != nil {
+		return err
+	}
+
+	if err := h.initMetrics(); err != nil {
+		return err
+	}
 
-	if viper.GetBool("log_json") {
-		cfg.Encoding = "json"
-	} else {
-		cfg.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	h.startupValues = restartValues()
+	h.currentSettings.Store(h.loadSettings())
+	h.limiter = newRateLimiter(viper.GetInt("rate_limit_max_clients"))
+	h.history = newRequestHistory(requestHistorySize())
+	h.concurrency = newConcurrencyLimiter()
+	h.basePath = basePath()
+	h.router = h.newRouter()
+	h.registerTCPChecks()
+
+	h.stopped = make(chan struct{})
+	h.serveErrs = make(chan error, 1)
+	servers, useTLS, err := h.newServers()
+	if err != nil {
+		return err
 	}
+	h.setServers(servers, nil)
 
-	cfg.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
+	stopSignals := h.listenSignals()
+	defer stopSignals()
+	h.watchConfig()
 
-	l, err := cfg.Build()
+	shared, listeners, err := listenAll(servers, nil)
 	if err != nil {
 		return err
 	}
+	h.setServers(servers, boundAddrs(listeners))
+	h.serverMu.Lock()
+	h.listeners = shared
+	h.serverMu.Unlock()
+
+	if err := h.serveAdmin(); err != nil {
+		closeUnusedListeners(shared, nil)
+		return err
+	}
+	if err := h.servePprof(); err != nil {
+		closeUnusedListeners(shared, nil)
+		return err
+	}
 
-	zap.ReplaceGlobals(l)
-	h.logger = l.Sugar()
+	h.ready.Store(true)
+	for _, ln := range listeners {
+		h.logger.Infow("Starting HTTP Server", "listen_addr", boundAddr(ln), "tls", useTLS)
+	}
 
-	return nil
+	return h.serveUntilStopped(servers, listeners, useTLS)
+}
+
+// serveUntilStopped serves servers on listeners until shutdown. If any of them fails, including servers
+// started later by listener restart, the rest are shut down too, so process is never left serving
+// on part of listen_addr addresses
+func (h *Handler) serveUntilStopped(servers []*http.Server, listeners []net.Listener, useTLS bool) error {
+	h.serveListeners(servers, listeners, useTLS)
+
+	// Serve returns as soon as Shutdown is called, either on shutdown or listener restart,
+	// so wait for in-flight requests to be drained
+	select {
+	case err := <-h.serveErrs:
+		h.GracefulShutdown("listener failure")
+		return err
+	case <-h.stopped:
+		return nil
+	}
 }
 
-func (h *Handler) initTracer() error {
-	jaegerUrl := viper.GetString("jaeger_trace")
-	if len(jaegerUrl) > 0 {
-		jaegerSrvName := fmt.Sprintf("busybox-%s", viper.GetString("env"))
-		address := viper.GetString("jaeger_addr")
-		exp, err := jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(jaegerUrl)))
+// listenAddrs returns main listener addresses, listen_addr is either a list or a comma separated string
+func listenAddrs() []string {
+	var addrs []string
+	for _, value := range viper.GetStringSlice("listen_addr") {
+		for _, addr := range strings.Split(value, ",") {
+			if addr = strings.TrimSpace(addr); len(addr) > 0 {
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+
+	if len(addrs) == 0 {
+		return []string{DefaultListenAddr}
+	}
+	return addrs
+}
+
+// newServers creates main HTTP server for every listen_addr address from current configuration
+func (h *Handler) newServers() ([]*http.Server, bool, error) {
+	var servers []*http.Server
+	var useTLS bool
+	for _, addr := range listenAddrs() {
+		server, tls, err := h.newServer(addr)
 		if err != nil {
-			return err
+			return nil, false, err
 		}
+		servers = append(servers, server)
+		useTLS = tls
+	}
+	return servers, useTLS, nil
+}
 
-		h.tracer = tracesdk.NewTracerProvider(
-			tracesdk.WithSampler(tracesdk.AlwaysSample()),
-			// Always be sure to batch in production.
-			tracesdk.WithBatcher(exp),
-			// Record information about this application in a Resource.
-			tracesdk.WithResource(resource.NewWithAttributes(
-				semconv.SchemaURL,
-				semconv.ServiceNameKey.String(jaegerSrvName),
-			)),
-		)
+// newServer creates main HTTP server listening to addr from current configuration
+func (h *Handler) newServer(addr string) (*http.Server, bool, error) {
+	useTLS, err := tlsEnabled()
+	if err != nil {
+		return nil, false, err
+	}
 
-		otel.SetTracerProvider(h.tracer)
+	server := &http.Server{
+		Addr:           addr,
+		Handler:        h,
+		MaxHeaderBytes: maxHeaderBytes(),
+	}
+	h.applyServerTimeouts(server)
 
-		h.logger.Debugw("Jaeger tracing client initialized", "collector_url", address)
+	if viper.GetBool("enable_h2c") {
+		// HTTP/2 over cleartext, HTTP/1.1 requests are still served by wrapped handler
+		server.Handler = h2c.NewHandler(h, &http2.Server{})
+	}
+
+	if useTLS {
+		if server.TLSConfig, err = newTLSConfig(); err != nil {
+			return nil, false, err
+		}
 	}
 
-	return nil
+	return server, useTLS, nil
 }
 
-func (h *Handler) Run() error {
-	if err := h.initLogger(); err != nil {
-		return err
+// serveListener accepts connections on listener, certificate files are read on every call
+func serveListener(server *http.Server, ln net.Listener, useTLS bool) error {
+	if useTLS {
+		return server.ServeTLS(ln, viper.GetString("tls_cert"), viper.GetString("tls_key"))
 	}
+	return server.Serve(ln)
+}
 
-	if err := h.initTracer(); err != nil {
-		return err
+// serveListeners serves every server on its listener in background,
+// serveErrs receives the first error of servers failed other than by shutdown
+func (h *Handler) serveListeners(servers []*http.Server, listeners []net.Listener, useTLS bool) {
+	for i := range servers {
+		server, ln := servers[i], listeners[i]
+		go func() {
+			if err := serveListener(server, ln, useTLS); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				h.logger.Errorw("HTTP server failed", "listen_addr", server.Addr, "err", err)
+				// one failure is enough to stop serving, the rest are only logged
+				select {
+				case h.serveErrs <- err:
+				default:
+				}
+			}
+		}()
 	}
+}
 
-	r := chi.NewRouter()
-	//r.Use(middleware.Logger) // i'm sure i have to log request response statuses in some way
-
-	// Go profiler
-	if viper.GetBool("enable_profiling") {
-		r.Mount("/debug/pprof", middleware.Profiler())
+// shutdownServers shuts servers down at once, so none of them accepts connections while others are drained
+func shutdownServers(ctx context.Context, servers []*http.Server) error {
+	errs := make([]error, len(servers))
+	var wg sync.WaitGroup
+	for i, server := range servers {
+		wg.Add(1)
+		go func(i int, server *http.Server) {
+			defer wg.Done()
+			if err := server.Shutdown(ctx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", server.Addr, err)
+			}
+		}(i, server)
 	}
+	wg.Wait()
 
-	// Prometheus metrics
-	r.Mount("/metrics", promhttp.Handler())
-	// service routes
-	r.Get("/health", h.healthCheck)
-	r.Route("/debug", func(cr chi.Router) {
-		cr.Get("/", h.mainHandler)
-		cr.Post("/", h.mainHandler)
-	})
+	return multierr.Combine(errs...)
+}
+
+// currentServers returns main HTTP servers, they are replaced on listener restart
+func (h *Handler) currentServers() []*http.Server {
+	h.serverMu.Lock()
+	defer h.serverMu.Unlock()
+	return h.servers
+}
 
-	h.router = r
+// boundListenAddrs returns addresses main HTTP servers are bound to
+func (h *Handler) boundListenAddrs() []string {
+	h.serverMu.Lock()
+	defer h.serverMu.Unlock()
+	return h.listenAddrs
+}
 
-	listenAddr := viper.GetString("listen_addr")
-	h.logger.Infow("Starting HTTP Server", "listen_addr", listenAddr)
-	return http.ListenAndServe(listenAddr, h)
+func (h *Handler) setServers(servers []*http.Server, listenAddrs []string) {
+	h.serverMu.Lock()
+	defer h.serverMu.Unlock()
+	h.servers = servers
+	h.listenAddrs = listenAddrs
 }
 
+func shutdownTimeout() time.Duration {
+	if timeout := viper.GetDuration("shutdown_timeout"); timeout > 0 {
+		return timeout
+	}
+	return defaultShutdownTimeout
+}
+
+// GracefulShutdown stops accepting new connections and waits up to shutdown_timeout
+// for in-flight requests to complete before running shutdown hooks
 func (h *Handler) GracefulShutdown(sig string) {
+	h.shutdownOnce.Do(func() {
+		h.shutdown(sig)
+	})
+}
+
+func (h *Handler) shutdown(sig string) {
 	if h.logger != nil {
 		h.logger.Warnf("Shutdown signal '%s' received", sig)
 	}
 
-	if h.tracer != nil {
-		h.tracer.Shutdown(context.Background())
+	// listener restart checks ready under the same lock before it swaps servers
+	h.serverMu.Lock()
+	h.ready.Store(false)
+	h.serverMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
+	defer cancel()
+
+	if servers := h.currentServers(); len(servers) > 0 {
+		drained := make(chan struct{})
+		go h.logDraining(drained)
+
+		if err := shutdownServers(ctx, servers); err != nil && h.logger != nil {
+			h.logger.Errorw("Unable to gracefully shutdown HTTP server", "err", err)
+		}
+		close(drained)
 	}
+	// server shutdown leaves sockets shared with restarted servers open
+	h.serverMu.Lock()
+	closeUnusedListeners(h.listeners, nil)
+	h.serverMu.Unlock()
+
+	for _, server := range h.auxServers {
+		if err := server.Shutdown(ctx); err != nil && h.logger != nil {
+			h.logger.Errorw("Unable to gracefully shutdown HTTP server", "listen_addr", server.Addr, "err", err)
+		}
+	}
+
+	// hooks get their own shutdown_timeout budget, so slow draining does not leave them without time
+	hooksCtx, cancelHooks := context.WithTimeout(context.Background(), shutdownTimeout())
+	defer cancelHooks()
 
-	os.Exit(0)
+	if err := h.runShutdownHooks(hooksCtx); err != nil && h.logger != nil {
+		h.logger.Errorw("Shutdown hooks failed", "err", err)
+	}
+
+	if h.stopped != nil {
+		close(h.stopped)
+	}
+}
+
+// logDraining periodically logs the number of in-flight requests until drained is closed
+func (h *Handler) logDraining(drained <-chan struct{}) {
+	if h.logger == nil {
+		return
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		h.logger.Infow("Draining in-flight requests", "inflight", h.inflight.Load())
+
+		select {
+		case <-drained:
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.serve(h.router, w, r)
+}
+
+// serve prepares request context with settings snapshot, client details and trace span,
+// and passes request to router. Main and auxiliary listeners share it
+func (h *Handler) serve(router http.Handler, w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	// Set request headers for AJAX requests
-	if origin := r.Header.Get("Origin"); origin != "" {
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		w.Header().Set("Access-Control-Allow-Origin", r.Header.Get("Origin"))
-		w.Header().Set("Access-Control-Allow-Methods", strings.Join(allowedMethods, headersSep))
-		w.Header().Set("Access-Control-Allow-Headers", strings.Join(allowedHeaders, headersSep))
-	}
+	h.inflight.Add(1)
+	defer h.inflight.Add(-1)
+
+	cfg := h.settings()
+	setCORSHeaders(w, r, cfg)
 
 	// handle preflight request
 	if r.Method == http.MethodOptions {
@@ -177,73 +425,95 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx = context.WithValue(ctx, "host", r.Host)
-	ctx = context.WithValue(ctx, "path", r.URL.Path)
-	ctx = context.WithValue(ctx, "remote_addr", r.RemoteAddr)
-	ctx = context.WithValue(ctx, "x_forwarded_for", r.Header.Get("X-Forwarded-For"))
+	ctx = context.WithValue(ctx, hostCtxKey, r.Host)
+	ctx = context.WithValue(ctx, pathCtxKey, r.URL.Path)
+	ctx = context.WithValue(ctx, remoteAddrCtxKey, r.RemoteAddr)
+	ctx = context.WithValue(ctx, xForwardedForCtxKey, r.Header.Get("X-Forwarded-For"))
+	ctx = context.WithValue(ctx, clientIPCtxKey, resolveClientIP(r, cfg.trustedProxies))
+	ctx = context.WithValue(ctx, settingsCtxKey, cfg)
 
 	if h.tracer != nil {
+		// continue upstream trace if request carries traceparent header
+		ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+
 		var span trace.Span
-		ctx, span = h.tracer.Tracer("http-interceptor").Start(ctx, r.URL.Path)
+		// span is renamed by matched route and gets response status once request is handled
+		ctx, span = h.tracer.Tracer("http-interceptor").Start(ctx, r.URL.Path, trace.WithSpanKind(trace.SpanKindServer))
 		span.SetAttributes(attribute.String("host", r.Host))
 		span.SetAttributes(attribute.String("method", r.Method))
 		defer span.End()
 	}
 
-	h.logger.Infow("Handling request", "method", r.Method, "path", r.URL.Path, "query", r.URL.RawQuery)
-
-	h.router.ServeHTTP(w, r.WithContext(ctx))
-}
-
-func (h *Handler) healthCheck(w http.ResponseWriter, r *http.Request) {
-	now := time.Now().Unix()
-	writeResponse(w, map[string]any{
-		"alive":     now - runDate.Unix(),
-		"version":   AppVersion,
-		"healthy":   true,
-		"timestamp": time.Now().Format(time.RFC1123),
-	})
+	router.ServeHTTP(w, r.WithContext(ctx))
 }
 
 func (h *Handler) mainHandler(w http.ResponseWriter, r *http.Request) {
-	results := make(map[string]any)
-	var headers []any
-	for name, values := range r.Header {
-		//h.logger.Debugf("%s: %s", name, values)
-		headers = append(headers, map[string]any{
-			"name":   name,
-			"values": values,
-		})
-	}
-	results["headers"] = headers
-
-	results["url"] = r.URL
-	results["user_agent"] = r.UserAgent()
-	results["remote_addr"] = r.RemoteAddr
-
-	if r.Method == http.MethodPost {
-		var bodyData map[string]any
-		decoder := json.NewDecoder(r.Body)
-		if err := decoder.Decode(&bodyData); err != nil {
-			h.logger.Errorw("Unable to decode body data", "err", err)
-			results["body_decoding_error"] = err.Error()
-		} else {
-			results["body"] = bodyData
-		}
+	// one-off response headers, e.g. ?header=Cache-Control:no-store
+	header, err := parseHeaderParams(r.URL.Query()["header"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	for name, values := range header {
+		w.Header()[name] = values
 	}
 
-	writeResponse(w, results)
+	results, ok := h.echoResults(w, r)
+	if !ok {
+		return
+	}
+	if len(results.BodyDecodingError) > 0 && strictBody(r) {
+		writeError(w, r, http.StatusBadRequest, "unable to decode body: "+results.BodyDecodingError)
+		return
+	}
+
+	writeResponse(w, r, results)
 }
 
-func writeResponse(w http.ResponseWriter, v any) {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+// echoResults collects incoming request details reported by echo handlers.
+// If request body exceeds max_body_bytes, 413 is responded and false is returned
+func (h *Handler) echoResults(w http.ResponseWriter, r *http.Request) (*EchoResponse, bool) {
+	results := &EchoResponse{
+		Method:  r.Method,
+		Headers: HeaderList(filterHeaders(r.Header, requestSettings(r))),
+		Cookies: requestCookies(r, requestSettings(r)),
+		URL:     r.URL,
+		// protocol request is received with, e.g. to detect proxy downgrading HTTP/2 to HTTP/1.1
+		Proto:      r.Proto,
+		ProtoMajor: r.ProtoMajor,
+		ProtoMinor: r.ProtoMinor,
+		Query:      r.URL.Query(),
+		UserAgent:  r.UserAgent(),
+		RemoteAddr: r.RemoteAddr,
+		ClientIP:   ClientIPFromContext(r.Context()),
+		RequestID:  RequestIDFromContext(r.Context()),
+	}
 
-	response, err := json.Marshal(v)
-	if err != nil {
-		w.Write([]byte("Cannot marshal response: " + err.Error()))
-		return
+	if limit := requestSettings(r).maxEchoedHeaders; limit > 0 && len(results.Headers) > limit {
+		results.HeadersTotal = len(results.Headers)
+		results.HeadersTruncated = true
+		results.Headers = results.Headers[:limit]
+	}
+
+	results.OriginalMethod = OriginalMethodFromContext(r.Context())
+
+	if r.TLS != nil {
+		results.TLS = tlsInfo(r.TLS)
+	}
+
+	if spanCtx := trace.SpanContextFromContext(r.Context()); spanCtx.IsValid() {
+		results.TraceID = spanCtx.TraceID().String()
+		results.SpanID = spanCtx.SpanID().String()
+	}
+
+	// body is decoded for any method, as long as request carries one
+	if r.Body != nil && r.Body != http.NoBody {
+		h.decodeBody(w, r, results)
+		if results.bodyTooLarge {
+			writeBodyTooLarge(w, r, requestSettings(r).maxBodyBytes)
+			return nil, false
+		}
 	}
 
-	w.WriteHeader(200)
-	w.Write(response)
+	return results, true
 }
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestServeUntilStoppedListenerFailure(t *testing.T) {
+	h := &Handler{logger: zap.NewNop().Sugar(), stopped: make(chan struct{}), serveErrs: make(chan error, 1)}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %s", err)
+	}
+	// closed listener makes the second server fail right away
+	failing, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %s", err)
+	}
+	failing.Close()
+
+	servers := []*http.Server{
+		{Addr: ln.Addr().String(), Handler: http.NotFoundHandler()},
+		{Addr: failing.Addr().String(), Handler: http.NotFoundHandler()},
+	}
+	h.setServers(servers, boundAddrs([]net.Listener{ln, failing}))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.serveUntilStopped(servers, []net.Listener{ln, failing}, false)
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("Expected listener failure to be returned")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected serving to stop once one of listeners failed")
+	}
+
+	select {
+	case <-h.stopped:
+	default:
+		t.Errorf("Expected handler to be stopped")
+	}
+	if conn, err := net.DialTimeout("tcp", ln.Addr().String(), time.Second); err == nil {
+		conn.Close()
+		t.Errorf("Expected remaining listener to be closed")
+	}
+}
+
+func TestServeUntilStoppedRestartedListenerFailure(t *testing.T) {
+	h := &Handler{logger: zap.NewNop().Sugar(), stopped: make(chan struct{}), serveErrs: make(chan error, 1)}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %s", err)
+	}
+	servers := []*http.Server{{Addr: ln.Addr().String(), Handler: http.NotFoundHandler()}}
+	h.setServers(servers, boundAddrs([]net.Listener{ln}))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.serveUntilStopped(servers, []net.Listener{ln}, false)
+	}()
+
+	// server started by listener restart fails after Run has started serving
+	failing, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %s", err)
+	}
+	failing.Close()
+	h.serveListeners([]*http.Server{{Addr: failing.Addr().String(), Handler: http.NotFoundHandler()}}, []net.Listener{failing}, false)
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("Expected restarted listener failure to be returned")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected serving to stop once restarted listener failed")
+	}
+	if conn, err := net.DialTimeout("tcp", ln.Addr().String(), time.Second); err == nil {
+		conn.Close()
+		t.Errorf("Expected remaining listener to be closed")
+	}
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"fmt"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"time"
+)
+
+// serverTimingWriter sets Server-Timing header once response header is about to be written,
+// so reported duration covers handler work done before the response starts
+type serverTimingWriter struct {
+	http.ResponseWriter
+	r     *http.Request
+	start time.Time
+
+	written bool
+}
+
+// setHeader reports handler duration and, if request is traced, total processing time since span start
+func (sw *serverTimingWriter) setHeader() {
+	if sw.written {
+		return
+	}
+	sw.written = true
+
+	now := time.Now()
+	value := serverTimingMetric("handler", now.Sub(sw.start))
+	if span, ok := trace.SpanFromContext(sw.r.Context()).(tracesdk.ReadOnlySpan); ok {
+		value += ", " + serverTimingMetric("total", now.Sub(span.StartTime()))
+	}
+	sw.Header().Set("Server-Timing", value)
+}
+
+func (sw *serverTimingWriter) WriteHeader(status int) {
+	// informational responses are not final, header is set with the final one
+	if status < 100 || status >= 200 || status == http.StatusSwitchingProtocols {
+		sw.setHeader()
+	}
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *serverTimingWriter) Write(p []byte) (int, error) {
+	sw.setHeader()
+	return sw.ResponseWriter.Write(p)
+}
+
+func (sw *serverTimingWriter) Flush() {
+	sw.setHeader()
+	if flusher, ok := sw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// serverTimingMetric formats Server-Timing metric with duration in milliseconds
+func serverTimingMetric(name string, d time.Duration) string {
+	return fmt.Sprintf("%s;dur=%.3f", name, float64(d)/float64(time.Millisecond))
+}
+
+// serverTiming middleware reports request processing time with Server-Timing response header,
+// so it is shown by browser developer tools
+func (h *Handler) serverTiming(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &serverTimingWriter{ResponseWriter: w, r: r, start: time.Now()}
+		defer sw.setHeader()
+		next.ServeHTTP(sw, r)
+	})
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"go.uber.org/zap/zapcore"
+	"math"
+	"net"
+	"net/http"
+	"time"
+)
+
+// settings is a snapshot of configuration applied at runtime without restarting the server.
+// Every request is served with the snapshot taken when it has started, so config reload
+// never changes settings in the middle of a request
+type settings struct {
+	corsAllowedOrigins   []string
+	corsAllowedMethods   []string
+	corsAllowedHeaders   []string
+	corsAllowCredentials bool
+
+	redactHeaders   []string
+	redactCookies   []string
+	headerAllowlist []string
+	// envEchoAllowlist lists environment variable name prefixes exposed by /debug/env
+	envEchoAllowlist []string
+	trustedProxies   []*net.IPNet
+	responseHeaders  http.Header
+	maxHeaderCount   int
+	// maxEchoedHeaders limits headers reported by echo, zero means no limit
+	maxEchoedHeaders int
+
+	authUsername string
+	authPassword string
+	bearerTokens []string
+
+	rateLimitRPS   float64
+	rateLimitBurst int
+
+	allowMethodOverride   bool
+	strictBody            bool
+	jsonUseNumber         bool
+	maxBodyBytes          int64
+	multipartMaxMemory    int64
+	maxDelay              time.Duration
+	maxRedirects          int
+	maxBytes              int64
+	readinessCheckTimeout time.Duration
+	requestTimeout        time.Duration
+	jsonIndent            string
+	compression           bool
+	compressionMinBytes   int
+
+	logResponseBodies       bool
+	logResponseBodyMaxBytes int
+	accessLogLevels         map[string]zapcore.Level
+}
+
+// restartRequiredKeys are applied only on server start, reload only warns if they are changed
+var restartRequiredKeys = []string{
+	"listen_addr",
+	"listen_network",
+	"tls_cert",
+	"tls_key",
+	"tls_min_version",
+	"tls_client_auth",
+	"tls_client_ca_file",
+	"read_header_timeout",
+	"read_timeout",
+	"write_timeout",
+	"idle_timeout",
+	"max_header_bytes",
+	"pprof_listen_addr",
+	"admin_listen_addr",
+	"request_history_size",
+	"reuse_port",
+	"max_concurrent_requests",
+	"base_path",
+}
+
+func (h *Handler) loadSettings() *settings {
+	rps := viper.GetFloat64("rate_limit_rps")
+	burst := viper.GetInt("rate_limit_burst")
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(rps)))
+	}
+
+	// invalid access_log_levels are reported by config validation and reload
+	levels, _ := accessLogLevels()
+
+	return &settings{
+		corsAllowedOrigins:   h.corsAllowedOrigins(),
+		corsAllowedMethods:   corsAllowedMethods(),
+		corsAllowedHeaders:   corsAllowedHeaders(),
+		corsAllowCredentials: h.corsAllowCredentials(),
+
+		redactHeaders:    redactHeaders(),
+		redactCookies:    viper.GetStringSlice("redact_cookies"),
+		headerAllowlist:  viper.GetStringSlice("header_allowlist"),
+		envEchoAllowlist: viper.GetStringSlice("env_echo_allowlist"),
+		trustedProxies:   parseTrustedProxies(viper.GetStringSlice("trusted_proxies")),
+		responseHeaders:  parseResponseHeaders(viper.GetStringMapString("response_headers")),
+		maxHeaderCount:   maxHeaderCount(),
+		maxEchoedHeaders: viper.GetInt("max_echoed_headers"),
+
+		authUsername: viper.GetString("auth_username"),
+		authPassword: viper.GetString("auth_password"),
+		bearerTokens: bearerTokens(),
+
+		rateLimitRPS:   rps,
+		rateLimitBurst: burst,
+
+		allowMethodOverride:   viper.GetBool("allow_method_override"),
+		strictBody:            viper.GetBool("strict_body"),
+		jsonUseNumber:         viper.GetBool("json_use_number"),
+		maxBodyBytes:          maxBodyBytes(),
+		multipartMaxMemory:    multipartMaxMemory(),
+		maxDelay:              maxDelay(),
+		maxRedirects:          maxRedirects(),
+		maxBytes:              maxBytes(),
+		readinessCheckTimeout: readinessCheckTimeout(),
+		requestTimeout:        viper.GetDuration("request_timeout"),
+		jsonIndent:            jsonIndent(),
+		compression:           viper.GetBool("enable_compression"),
+		compressionMinBytes:   compressionMinBytes(),
+
+		logResponseBodies:       viper.GetBool("log_response_bodies"),
+		logResponseBodyMaxBytes: logResponseBodyMaxBytes(),
+		accessLogLevels:         levels,
+	}
+}
+
+// settings returns current runtime settings snapshot
+func (h *Handler) settings() *settings {
+	if s := h.currentSettings.Load(); s != nil {
+		return s
+	}
+	return h.loadSettings()
+}
+
+// requestSettings returns settings snapshot request is served with,
+// requests not passed through Handler get settings loaded from current config
+func requestSettings(r *http.Request) *settings {
+	if s, ok := r.Context().Value(settingsCtxKey).(*settings); ok {
+		return s
+	}
+	return new(Handler).loadSettings()
+}
+
+// restartValues captures values of settings which can not be changed without restart
+func restartValues() map[string]any {
+	values := make(map[string]any, len(restartRequiredKeys))
+	for _, key := range restartRequiredKeys {
+		values[key] = viper.Get(key)
+	}
+	return values
+}
+
+// Reload re-reads config file, if one is used, and applies runtime settings.
+// Settings requiring listener restart are reported, but not applied
+func (h *Handler) Reload() error {
+	if len(viper.ConfigFileUsed()) > 0 {
+		if err := viper.ReadInConfig(); err != nil {
+			return err
+		}
+	}
+
+	h.applySettings()
+	return nil
+}
+
+// changedRestartKeys returns restart required keys changed since server has started
+func (h *Handler) changedRestartKeys() []string {
+	h.serverMu.Lock()
+	defer h.serverMu.Unlock()
+
+	var changed []string
+	for key, value := range restartValues() {
+		if started, ok := h.startupValues[key]; ok && fmt.Sprint(started) != fmt.Sprint(value) {
+			changed = append(changed, key)
+		}
+	}
+	return changed
+}
+
+func (h *Handler) applySettings() {
+	h.currentSettings.Store(h.loadSettings())
+	h.applyLogLevel()
+	if _, err := accessLogLevels(); err != nil {
+		h.logger.Errorw("Invalid access log levels, defaults are used instead", "err", err)
+	}
+
+	for _, key := range h.changedRestartKeys() {
+		h.logger.Warnw("Config value changed, restart is required to apply it", "key", key)
+	}
+
+	h.logger.Infow("Runtime settings applied", "config_file", viper.ConfigFileUsed())
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"context"
+	"go.uber.org/multierr"
+)
+
+// ShutdownHook releases resources on GracefulShutdown, e.g. flushes buffers or closes custom exporters
+type ShutdownHook func(ctx context.Context) error
+
+// OnShutdown adds hook run by GracefulShutdown once servers are drained, hooks run in reverse order
+// of registration and share context bounded by shutdown_timeout of their own, not the one left after draining
+func (h *Handler) OnShutdown(fn func(ctx context.Context) error) {
+	h.hooksMu.Lock()
+	defer h.hooksMu.Unlock()
+
+	h.shutdownHooks = append(h.shutdownHooks, fn)
+}
+
+// runShutdownHooks runs registered hooks in LIFO order, every hook runs even if previous one failed
+func (h *Handler) runShutdownHooks(ctx context.Context) error {
+	h.hooksMu.Lock()
+	hooks := make([]ShutdownHook, len(h.shutdownHooks))
+	copy(hooks, h.shutdownHooks)
+	h.hooksMu.Unlock()
+
+	var err error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		err = multierr.Append(err, hooks[i](ctx))
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"reflect"
+	"testing"
+)
+
+func TestShutdownHooks(t *testing.T) {
+	h := &Handler{logger: zap.NewNop().Sugar()}
+
+	var order []int
+	var deadlines int
+	for i := 1; i <= 3; i++ {
+		i := i
+		h.OnShutdown(func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); ok {
+				deadlines++
+			}
+			order = append(order, i)
+			if i != 2 {
+				return errors.New("hook failed")
+			}
+			return nil
+		})
+	}
+
+	h.GracefulShutdown("test")
+	if expected := []int{3, 2, 1}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected hooks to run in %v order, got: %v", expected, order)
+	}
+	if deadlines != 3 {
+		t.Errorf("Expected every hook to receive drain context with deadline, got %d", deadlines)
+	}
+
+	order = nil
+	err := h.runShutdownHooks(context.Background())
+	if errs := multierr.Errors(err); len(errs) != 2 {
+		t.Errorf("Expected 2 aggregated hook errors, got: %v", err)
+	}
+	if len(order) != 3 {
+		t.Errorf("Expected failed hooks not to stop the rest, ran: %v", order)
+	}
+}
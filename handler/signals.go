@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// listenSignals traps SIGINT, SIGTERM and SIGHUP and calls GracefulShutdown on receive.
+// SIGHUP reloads config and applies runtime settings instead when reload_on_sighup is enabled.
+// Returned function stops listening and releases the goroutine
+func (h *Handler) listenSignals() func() {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-sigChan:
+				if sig == syscall.SIGHUP && viper.GetBool("reload_on_sighup") {
+					h.reloadConfig()
+					continue
+				}
+
+				h.GracefulShutdown(sig.String())
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigChan)
+		close(done)
+	}
+}
+
+// reloadConfig applies runtime settings and restarts main listener
+// if its settings have changed and reuse_port is enabled
+func (h *Handler) reloadConfig() {
+	if err := h.Reload(); err != nil {
+		h.logger.Errorw("Unable to reload config", "err", err)
+		return
+	}
+
+	if viper.GetBool("reuse_port") && h.listenerChanged() {
+		if err := h.restartListener(); err != nil {
+			h.logger.Errorw("Unable to restart HTTP server listener", "err", err)
+		}
+	}
+}
+
+// watchConfig applies runtime settings whenever config file is changed, if watch_config is enabled
+func (h *Handler) watchConfig() {
+	if !viper.GetBool("watch_config") || len(viper.ConfigFileUsed()) == 0 {
+		return
+	}
+
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		h.logger.Infow("Config file changed", "name", e.Name, "op", e.Op.String())
+		h.applySettings()
+	})
+	viper.WatchConfig()
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"github.com/go-chi/chi/v5"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// statusHandler responds with status code given in path.
+// If a comma-separated list of codes is given, one of them is chosen at random.
+// Informational 1xx codes are rejected, as they can not be the final response status
+func (h *Handler) statusHandler(w http.ResponseWriter, r *http.Request) {
+	var codes []int
+	for _, value := range strings.Split(chi.URLParam(r, "codes"), ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || code < 200 || code > 599 {
+			writeError(w, r, http.StatusBadRequest, "invalid status code: "+value)
+			return
+		}
+		codes = append(codes, code)
+	}
+
+	code := codes[0]
+	if len(codes) > 1 {
+		code = codes[rand.Intn(len(codes))]
+	}
+
+	writeStatusResponse(w, r, code, map[string]any{
+		"status": code,
+	})
+}
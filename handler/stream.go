@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	maxStreamLines = 100
+
+	defaultSSEInterval = time.Second
+
+	defaultDripDuration = 2 * time.Second
+	defaultDripBytes    = 10
+)
+
+// streamHandler writes n newline delimited JSON objects, flushing each of them to the client.
+// n is capped by 100 lines, same as httpbin does
+func (h *Handler) streamHandler(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(chi.URLParam(r, "n"))
+	if err != nil || n < 0 {
+		writeError(w, r, http.StatusBadRequest, "invalid number of lines")
+		return
+	}
+
+	if n > maxStreamLines {
+		n = maxStreamLines
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	for i := 0; i < n; i++ {
+		if err := r.Context().Err(); err != nil {
+			h.requestLogger(r).Debugw("Client disconnected during stream", "sent", i, "total", n)
+			return
+		}
+
+		if err := encoder.Encode(map[string]any{
+			"seq":       i,
+			"timestamp": time.Now().Format(time.RFC3339Nano),
+		}); err != nil {
+			h.requestLogger(r).Errorw("Unable to write stream line", "err", err)
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// sseHandler emits server-sent events with incrementing counter every interval
+// until client disconnects or count events are sent
+func (h *Handler) sseHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	interval := defaultSSEInterval
+	if value := query.Get("interval"); len(value) > 0 {
+		var err error
+		if interval, err = time.ParseDuration(value); err != nil || interval <= 0 {
+			writeError(w, r, http.StatusBadRequest, "invalid interval")
+			return
+		}
+	}
+
+	count := -1
+	if value := query.Get("count"); len(value) > 0 {
+		var err error
+		if count, err = strconv.Atoi(value); err != nil || count < 0 {
+			writeError(w, r, http.StatusBadRequest, "invalid count")
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for i := 0; count < 0 || i < count; i++ {
+		select {
+		case <-r.Context().Done():
+			h.requestLogger(r).Debugw("Client disconnected from events stream", "sent", i)
+			return
+		case <-ticker.C:
+		}
+
+		data, err := json.Marshal(map[string]any{
+			"count":     i,
+			"timestamp": time.Now().Format(time.RFC3339Nano),
+		})
+		if err != nil {
+			h.requestLogger(r).Errorw("Unable to marshal event", "err", err)
+			return
+		}
+
+		if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", i, data); err != nil {
+			h.requestLogger(r).Errorw("Unable to write event", "err", err)
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// durationParam parses non-negative duration query param, fallback is returned if it is absent
+func durationParam(r *http.Request, name string, fallback time.Duration) (time.Duration, error) {
+	value := r.URL.Query().Get(name)
+	if len(value) == 0 {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+	return d, nil
+}
+
+// dripHandler waits for initial delay and writes numbytes bytes evenly spread across duration,
+// flushing each of them. Delay and duration are capped by max_delay in total and numbytes by max_bytes
+func (h *Handler) dripHandler(w http.ResponseWriter, r *http.Request) {
+	cfg := requestSettings(r)
+
+	duration, err := durationParam(r, "duration", defaultDripDuration)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	delay, err := durationParam(r, "delay", 0)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	numBytes := int64(defaultDripBytes)
+	if value := r.URL.Query().Get("numbytes"); len(value) > 0 {
+		if numBytes, err = strconv.ParseInt(value, 10, 64); err != nil || numBytes < 0 {
+			writeError(w, r, http.StatusBadRequest, "invalid numbytes")
+			return
+		}
+	}
+
+	// delay and duration are capped in total, so response never takes longer than max_delay
+	if delay > cfg.maxDelay {
+		delay = cfg.maxDelay
+	}
+	if duration > cfg.maxDelay-delay {
+		duration = cfg.maxDelay - delay
+	}
+	if numBytes > cfg.maxBytes {
+		numBytes = cfg.maxBytes
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-r.Context().Done():
+		h.requestLogger(r).Debugw("Client disconnected before drip delay elapsed", "delay", delay.String())
+		return
+	case <-timer.C:
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(numBytes, 10))
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var interval time.Duration
+	if numBytes > 0 {
+		interval = duration / time.Duration(numBytes)
+	}
+
+	for i := int64(0); i < numBytes; i++ {
+		// timer is drained at this point, so it is safe to reset
+		timer.Reset(interval)
+		select {
+		case <-r.Context().Done():
+			h.requestLogger(r).Debugw("Client disconnected during drip", "sent", i, "total", numBytes)
+			return
+		case <-timer.C:
+		}
+
+		if _, err := w.Write([]byte{'*'}); err != nil {
+			h.requestLogger(r).Errorw("Unable to write drip byte", "err", err)
+			return
+		}
+		flusher.Flush()
+	}
+}
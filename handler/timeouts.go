@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/spf13/viper"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultReadHeaderTimeout = 10 * time.Second
+	defaultReadTimeout       = 60 * time.Second
+	defaultWriteTimeout      = 90 * time.Second
+	defaultIdleTimeout       = 120 * time.Second
+)
+
+// serverTimeout reads server timeout value, fallback is used if it is not set and negative value disables timeout
+func serverTimeout(key string, fallback time.Duration) time.Duration {
+	timeout := viper.GetDuration(key)
+	switch {
+	case timeout < 0:
+		return 0
+	case timeout == 0:
+		return fallback
+	}
+	return timeout
+}
+
+// setServerTimeouts sets configured timeouts to server, it is used by main and auxiliary servers alike
+func setServerTimeouts(server *http.Server) {
+	server.ReadHeaderTimeout = serverTimeout("read_header_timeout", defaultReadHeaderTimeout)
+	server.ReadTimeout = serverTimeout("read_timeout", defaultReadTimeout)
+	server.WriteTimeout = serverTimeout("write_timeout", defaultWriteTimeout)
+	server.IdleTimeout = serverTimeout("idle_timeout", defaultIdleTimeout)
+}
+
+// applyServerTimeouts sets server timeouts protecting it from slow clients.
+// write_timeout bounds whole response, so it has to exceed max_delay,
+// and long /stream or /sse responses are cut once it is elapsed
+func (h *Handler) applyServerTimeouts(server *http.Server) {
+	setServerTimeouts(server)
+
+	if server.WriteTimeout > 0 && server.WriteTimeout <= maxDelay() {
+		h.logger.Warnw("Write timeout does not exceed max delay, delayed responses would be cut",
+			"write_timeout", server.WriteTimeout.String(), "max_delay", maxDelay().String())
+	}
+}
+
+// requestTimeout attaches request_timeout deadline to request context, handlers stop once it is exceeded.
+// Timed out request is responded with 503 unless handler has written response already,
+// e.g. /stream or /sse responses are just cut
+func (h *Handler) requestTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := requestSettings(r).requestTimeout
+		if timeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && ww.Status() == 0 && r.Context().Err() == nil {
+			h.requestLogger(r).Warnw("Request timeout exceeded", "request_timeout", timeout.String())
+			writeError(w, r, http.StatusServiceUnavailable, "request timeout exceeded")
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"strings"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsClientAuthTypes are supported tls_client_auth values, client certificates are verified
+// against tls_client_ca_file if set, or system roots otherwise
+var tlsClientAuthTypes = map[string]tls.ClientAuthType{
+	"none":            tls.NoClientCert,
+	"request":         tls.RequestClientCert,
+	"verify_if_given": tls.VerifyClientCertIfGiven,
+	"require":         tls.RequireAndVerifyClientCert,
+}
+
+// tlsEnabled reports whether both tls_cert and tls_key are set,
+// and returns an error if only one of them is configured
+func tlsEnabled() (bool, error) {
+	certFile := viper.GetString("tls_cert")
+	keyFile := viper.GetString("tls_key")
+
+	if len(certFile) == 0 && len(keyFile) == 0 {
+		return false, nil
+	}
+
+	if len(certFile) == 0 || len(keyFile) == 0 {
+		return false, fmt.Errorf("both tls_cert and tls_key must be set to enable TLS")
+	}
+
+	return true, nil
+}
+
+func newTLSConfig() (*tls.Config, error) {
+	cfg := new(tls.Config)
+
+	if minVersion := viper.GetString("tls_min_version"); len(minVersion) > 0 {
+		version, ok := tlsVersions[minVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tls_min_version '%s'", minVersion)
+		}
+		cfg.MinVersion = version
+	}
+
+	if clientAuth := viper.GetString("tls_client_auth"); len(clientAuth) > 0 {
+		authType, ok := tlsClientAuthTypes[strings.ToLower(clientAuth)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tls_client_auth '%s'", clientAuth)
+		}
+		cfg.ClientAuth = authType
+	}
+
+	if caFile := viper.GetString("tls_client_ca_file"); len(caFile) > 0 {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = pool
+	}
+
+	return cfg, nil
+}
+
+// loadCertPool reads PEM encoded CA certificates from file
+func loadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read tls_client_ca_file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in tls_client_ca_file '%s'", file)
+	}
+	return pool, nil
+}
+
+func tlsVersionName(version uint16) string {
+	for name, v := range tlsVersions {
+		if v == version {
+			return name
+		}
+	}
+	return fmt.Sprintf("0x%04x", version)
+}
+
+// TLSInfo describes negotiated TLS connection parameters
+func TLSInfo(state *tls.ConnectionState) map[string]any {
+	return map[string]any{
+		"version":             tlsVersionName(state.Version),
+		"cipher_suite":        tls.CipherSuiteName(state.CipherSuite),
+		"server_name":         state.ServerName,
+		"negotiated_protocol": state.NegotiatedProtocol,
+		"resumed":             state.DidResume,
+	}
+}
+
+// tlsInfo describes TLS connection of incoming request, including client certificate subject
+func tlsInfo(state *tls.ConnectionState) map[string]any {
+	info := TLSInfo(state)
+	if len(state.PeerCertificates) > 0 {
+		info["client_cert_subject"] = state.PeerCertificates[0].Subject.String()
+	}
+
+	return info
+}
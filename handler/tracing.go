@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	traceExporterJaeger   = "jaeger"
+	traceExporterOtlpGrpc = "otlp-grpc"
+	traceExporterOtlpHttp = "otlp-http"
+
+	traceSamplerAlways           = "always"
+	traceSamplerNever            = "never"
+	traceSamplerRatio            = "ratio"
+	traceSamplerParentBasedRatio = "parentbased_ratio"
+
+	defaultSampleRatio           = 1.0
+	defaultProductionSampleRatio = 0.1
+
+	defaultTraceShutdownTimeout = 5 * time.Second
+)
+
+// traceExporterName returns configured trace_exporter,
+// falls back to jaeger if only jaeger_trace collector address is set
+func traceExporterName() string {
+	if name := viper.GetString("trace_exporter"); len(name) > 0 {
+		return name
+	}
+
+	if len(viper.GetString("jaeger_trace")) > 0 {
+		return traceExporterJaeger
+	}
+
+	return ""
+}
+
+// otlpEndpoint splits otlp_endpoint into host:port, url path and insecure flag.
+// Endpoint may be set as url, e.g. "http://otel-collector:4318/v1/traces", or as host:port.
+// Empty endpoint means exporter would use OTEL_EXPORTER_OTLP_* env vars or its defaults
+func otlpEndpoint() (host string, path string, insecure bool) {
+	endpoint := viper.GetString("otlp_endpoint")
+	if u, err := url.Parse(endpoint); err == nil && len(u.Host) > 0 {
+		return u.Host, u.Path, u.Scheme == "http"
+	}
+	return endpoint, "", false
+}
+
+// traceSampleRatio returns trace_sample_ratio, not set or negative ratio defaults to 1.0,
+// which samples every trace, or to 0.1 in production
+func (h *Handler) traceSampleRatio() float64 {
+	if viper.IsSet("trace_sample_ratio") {
+		if ratio := viper.GetFloat64("trace_sample_ratio"); ratio >= 0 {
+			return ratio
+		}
+	}
+	if h.isProduction() {
+		return defaultProductionSampleRatio
+	}
+	return defaultSampleRatio
+}
+
+// newSampler builds sampler from trace_sampler and trace_sample_ratio,
+// defaults to parent based sampler
+func (h *Handler) newSampler() (tracesdk.Sampler, error) {
+	ratio := h.traceSampleRatio()
+	if ratio > 1 {
+		return nil, fmt.Errorf("trace_sample_ratio must be within [0, 1], got %v", ratio)
+	}
+
+	switch name := viper.GetString("trace_sampler"); name {
+	case traceSamplerAlways:
+		return tracesdk.AlwaysSample(), nil
+	case traceSamplerNever:
+		return tracesdk.NeverSample(), nil
+	case traceSamplerRatio:
+		return tracesdk.TraceIDRatioBased(ratio), nil
+	case traceSamplerParentBasedRatio, "":
+		return tracesdk.ParentBased(tracesdk.TraceIDRatioBased(ratio)), nil
+	default:
+		return nil, fmt.Errorf("unsupported trace_sampler '%s'", name)
+	}
+}
+
+func newSpanExporter(ctx context.Context, name string) (tracesdk.SpanExporter, error) {
+	switch name {
+	case traceExporterJaeger:
+		return jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(viper.GetString("jaeger_trace"))))
+	case traceExporterOtlpGrpc:
+		var opts []otlptracegrpc.Option
+		if host, _, insecure := otlpEndpoint(); len(host) > 0 {
+			opts = append(opts, otlptracegrpc.WithEndpoint(host))
+			if insecure {
+				opts = append(opts, otlptracegrpc.WithInsecure())
+			}
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case traceExporterOtlpHttp:
+		var opts []otlptracehttp.Option
+		if host, path, insecure := otlpEndpoint(); len(host) > 0 {
+			opts = append(opts, otlptracehttp.WithEndpoint(host))
+			if len(path) > 0 {
+				opts = append(opts, otlptracehttp.WithURLPath(path))
+			}
+			if insecure {
+				opts = append(opts, otlptracehttp.WithInsecure())
+			}
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported trace_exporter '%s'", name)
+	}
+}
+
+// serviceName returns service_name, defaults to busybox-<env>
+func serviceName() string {
+	if name := viper.GetString("service_name"); len(name) > 0 {
+		return name
+	}
+	return fmt.Sprintf("busybox-%s", viper.GetString("env"))
+}
+
+// serviceResource describes this application for exported spans and metrics,
+// service_name and service_version take precedence over the same keys of resource_attributes
+func serviceResource() *resource.Resource {
+	var attrs []attribute.KeyValue
+	for key, value := range viper.GetStringMapString("resource_attributes") {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+
+	attrs = append(attrs, semconv.ServiceNameKey.String(serviceName()))
+	version := viper.GetString("service_version")
+	if len(version) == 0 {
+		version = AppVersion
+	}
+	if len(version) > 0 {
+		attrs = append(attrs, semconv.ServiceVersionKey.String(version))
+	}
+
+	// attribute set keeps the last value of duplicated keys
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...)
+}
+
+// countingSpanExporter counts failed span exports, batch span processor reports the errors
+// to OTel error handler, which otherwise prints them to stderr and keeps no trace of them
+type countingSpanExporter struct {
+	tracesdk.SpanExporter
+	errors *atomic.Int64
+}
+
+func (e *countingSpanExporter) ExportSpans(ctx context.Context, spans []tracesdk.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	if err != nil {
+		e.errors.Add(1)
+	}
+	return err
+}
+
+// otelErrorHandler logs errors of OTel SDK, e.g. failed span or metric exports
+func (h *Handler) otelErrorHandler() otel.ErrorHandler {
+	return otel.ErrorHandlerFunc(func(err error) {
+		h.logger.Warnw("OpenTelemetry error", "err", err)
+	})
+}
+
+func (h *Handler) initTracer() error {
+	exporterName := traceExporterName()
+	if len(exporterName) == 0 {
+		return nil
+	}
+
+	if exporterName == traceExporterJaeger {
+		h.logger.Warn("Jaeger trace exporter is deprecated, consider switching trace_exporter to otlp-grpc or otlp-http")
+	}
+
+	sampler, err := h.newSampler()
+	if err != nil {
+		return err
+	}
+
+	exp, err := newSpanExporter(context.Background(), exporterName)
+	if err != nil {
+		// tracing is optional unless trace_required is set, debug server is needed most when infrastructure is broken
+		if viper.GetBool("trace_required") {
+			return err
+		}
+		h.logger.Warnw("Unable to initialize trace exporter, tracing is disabled", "exporter", exporterName, "err", err)
+		return nil
+	}
+
+	h.tracer = tracesdk.NewTracerProvider(
+		tracesdk.WithSampler(sampler),
+		// Always be sure to batch in production.
+		tracesdk.WithBatcher(&countingSpanExporter{SpanExporter: exp, errors: &h.traceExportErrors}),
+		// Record information about this application in a Resource.
+		tracesdk.WithResource(serviceResource()),
+	)
+
+	h.OnShutdown(h.shutdownTracer)
+	otel.SetTracerProvider(h.tracer)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	h.logger.Debugw("Tracing client initialized", "exporter", exporterName, "sampler", sampler.Description())
+
+	return nil
+}
+
+func traceShutdownTimeout() time.Duration {
+	if timeout := viper.GetDuration("trace_shutdown_timeout"); timeout > 0 {
+		return timeout
+	}
+	return defaultTraceShutdownTimeout
+}
+
+// shutdownTracer is a shutdown hook flushing pending spans, it gives up after trace_shutdown_timeout
+// so unavailable collector does not block shutdown
+func (h *Handler) shutdownTracer(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, traceShutdownTimeout())
+	defer cancel()
+
+	if err := h.tracer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("unable to flush spans: %w", err)
+	}
+	return nil
+}
+
+// recordSpanResponse sets response status and matched route to request span,
+// 5xx responses mark span as failed
+func recordSpanResponse(r *http.Request, status int) {
+	span := trace.SpanFromContext(r.Context())
+	if !span.IsRecording() {
+		return
+	}
+
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+	if route := routePattern(r); route != unknownRoute {
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(semconv.HTTPRouteKey.String(route))
+	}
+
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(status, trace.SpanKindServer))
+}
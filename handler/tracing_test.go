@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestInitTracerExporterFailure(t *testing.T) {
+	viper.Set("trace_exporter", "unsupported")
+	defer viper.Set("trace_exporter", nil)
+
+	h := &Handler{logger: zap.NewNop().Sugar()}
+	if err := h.initTracer(); err != nil {
+		t.Fatalf("Exporter failure must not prevent start: %s", err)
+	}
+	if h.tracer != nil {
+		t.Errorf("Tracing must be disabled if exporter is not initialized")
+	}
+
+	viper.Set("trace_required", true)
+	defer viper.Set("trace_required", nil)
+
+	if err := h.initTracer(); err == nil {
+		t.Errorf("Exporter failure must prevent start if trace_required is set")
+	}
+}
+
+// blockingExporter never completes export until context is done, as unavailable collector does
+type blockingExporter struct{}
+
+func (blockingExporter) ExportSpans(ctx context.Context, _ []tracesdk.ReadOnlySpan) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (blockingExporter) Shutdown(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// failingExporter rejects every export, as unreachable collector does
+type failingExporter struct{}
+
+func (failingExporter) ExportSpans(context.Context, []tracesdk.ReadOnlySpan) error {
+	return errors.New("collector is unavailable")
+}
+
+func (failingExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestTraceExportErrors(t *testing.T) {
+	h := &Handler{logger: zap.NewNop().Sugar()}
+	tracer := tracesdk.NewTracerProvider(tracesdk.WithSyncer(&countingSpanExporter{
+		SpanExporter: failingExporter{},
+		errors:       &h.traceExportErrors,
+	}))
+	defer tracer.Shutdown(context.Background())
+
+	for i := 0; i < 2; i++ {
+		_, span := tracer.Tracer("test").Start(context.Background(), "test")
+		span.End()
+	}
+
+	if count := h.traceExportErrors.Load(); count != 2 {
+		t.Errorf("Expected 2 failed exports, got: %d", count)
+	}
+}
+
+func TestShutdownTracerTimeout(t *testing.T) {
+	viper.Set("trace_shutdown_timeout", 100*time.Millisecond)
+	defer viper.Set("trace_shutdown_timeout", nil)
+
+	h := &Handler{
+		logger: zap.NewNop().Sugar(),
+		tracer: tracesdk.NewTracerProvider(tracesdk.WithBatcher(blockingExporter{})),
+	}
+	_, span := h.tracer.Tracer("test").Start(context.Background(), "test")
+	span.End()
+
+	done := make(chan struct{})
+	go func() {
+		h.shutdownTracer(context.Background())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Tracer shutdown must not block longer than trace_shutdown_timeout")
+	}
+}
+
+func TestSpanResponseAttributes(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	h := &Handler{
+		logger:  zap.NewNop().Sugar(),
+		tracer:  tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder)),
+		limiter: newRateLimiter(0),
+	}
+	if err := h.initMetrics(); err != nil {
+		t.Fatalf("Unable to init metrics: %s", err)
+	}
+	h.currentSettings.Store(h.loadSettings())
+	h.router = h.newRouter()
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/status/503", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+
+	span := spans[0]
+	if span.Name() != "GET /status/{codes}" {
+		t.Errorf("Expected span to be named by route, got: %s", span.Name())
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("Expected error span status for 5xx response, got: %s", span.Status().Code)
+	}
+
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range span.Attributes() {
+		attrs[attr.Key] = attr.Value
+	}
+	if attrs["http.status_code"].AsInt64() != http.StatusServiceUnavailable {
+		t.Errorf("Expected http.status_code attribute, got: %v", attrs["http.status_code"].Emit())
+	}
+	if attrs["http.route"].AsString() != "/status/{codes}" {
+		t.Errorf("Expected http.route attribute, got: %v", attrs["http.route"].Emit())
+	}
+}
+
+func TestServiceResource(t *testing.T) {
+	values := map[string]any{
+		"env":                 "staging",
+		"resource_attributes": map[string]string{"deployment.environment": "staging", "service.name": "ignored"},
+	}
+	for key, value := range values {
+		viper.Set(key, value)
+	}
+	defer func() {
+		for key := range values {
+			viper.Set(key, nil)
+		}
+		viper.Set("service_name", nil)
+		viper.Set("service_version", nil)
+	}()
+
+	attrs := serviceResource().Set()
+	if name, _ := attrs.Value("service.name"); name.AsString() != "busybox-staging" {
+		t.Errorf("Expected default service name busybox-staging, got: %s", name.AsString())
+	}
+	if env, _ := attrs.Value("deployment.environment"); env.AsString() != "staging" {
+		t.Errorf("Expected resource attribute to be set, got: %s", env.AsString())
+	}
+
+	viper.Set("service_name", "busybox-canary")
+	viper.Set("service_version", "v1.2.3")
+	attrs = serviceResource().Set()
+	if name, _ := attrs.Value("service.name"); name.AsString() != "busybox-canary" {
+		t.Errorf("Expected configured service name, got: %s", name.AsString())
+	}
+	if version, _ := attrs.Value("service.version"); version.AsString() != "v1.2.3" {
+		t.Errorf("Expected configured service version, got: %s", version.AsString())
+	}
+}
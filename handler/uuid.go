@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"net/http"
+)
+
+// uuidHandler responds with random version 4 UUID, same as httpbin does
+func (h *Handler) uuidHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := newUUID()
+	if err != nil {
+		h.requestLogger(r).Errorw("Unable to generate UUID", "err", err)
+		writeError(w, r, http.StatusInternalServerError, "unable to generate uuid")
+		return
+	}
+
+	writeResponse(w, r, map[string]any{
+		"uuid": id,
+	})
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"go.uber.org/multierr"
+	"strconv"
+)
+
+// nonNegativeDurationKeys are durations which make no sense below zero,
+// server timeouts are not listed as negative value disables them
+var nonNegativeDurationKeys = []string{
+	"max_delay",
+	"readiness_check_timeout",
+	"shutdown_timeout",
+	"metrics_export_interval",
+	"trace_shutdown_timeout",
+	"request_timeout",
+}
+
+// validateConfig checks configuration for invalid values and conflicting options,
+// all found problems are reported at once
+func (h *Handler) validateConfig() error {
+	var err error
+
+	if _, tlsErr := tlsEnabled(); tlsErr != nil {
+		err = multierr.Append(err, tlsErr)
+	}
+	if _, tlsErr := newTLSConfig(); tlsErr != nil {
+		err = multierr.Append(err, tlsErr)
+	}
+
+	mainAddrs := make(map[string]bool)
+	for _, addr := range listenAddrs() {
+		if mainAddrs[addr] {
+			err = multierr.Append(err, fmt.Errorf("listen_addr '%s' is listed more than once", addr))
+		}
+		mainAddrs[addr] = true
+	}
+	for _, key := range []string{"admin_listen_addr", "pprof_listen_addr"} {
+		if addr := viper.GetString(key); len(addr) > 0 && mainAddrs[addr] {
+			err = multierr.Append(err, fmt.Errorf("%s must differ from listen_addr '%s'", key, addr))
+		}
+	}
+	if adminAddr := viper.GetString("admin_listen_addr"); len(adminAddr) > 0 && adminAddr == viper.GetString("pprof_listen_addr") {
+		err = multierr.Append(err, fmt.Errorf("pprof_listen_addr must differ from admin_listen_addr '%s'", adminAddr))
+	}
+
+	for _, key := range nonNegativeDurationKeys {
+		if viper.GetDuration(key) < 0 {
+			err = multierr.Append(err, fmt.Errorf("%s must not be negative", key))
+		}
+	}
+	if viper.GetFloat64("rate_limit_rps") < 0 {
+		err = multierr.Append(err, fmt.Errorf("rate_limit_rps must not be negative"))
+	}
+	if network := viper.GetString("listen_network"); len(network) > 0 && !containsFold(listenNetworks, network) {
+		err = multierr.Append(err, fmt.Errorf("invalid listen_network '%s', expected one of tcp, tcp4, tcp6", network))
+	}
+	if viper.GetInt("max_concurrent_requests") < 0 {
+		err = multierr.Append(err, fmt.Errorf("max_concurrent_requests must not be negative"))
+	}
+	if viper.GetInt("max_echoed_headers") < 0 {
+		err = multierr.Append(err, fmt.Errorf("max_echoed_headers must not be negative"))
+	}
+
+	if value := viper.GetString("cors_allow_credentials"); len(value) > 0 && value != corsAllowCredentialsAuto {
+		if _, parseErr := strconv.ParseBool(value); parseErr != nil {
+			err = multierr.Append(err, fmt.Errorf("invalid cors_allow_credentials '%s', expected true, false or auto", value))
+		}
+	}
+	if _, levelsErr := accessLogLevels(); levelsErr != nil {
+		err = multierr.Append(err, levelsErr)
+	}
+	if _, samplerErr := h.newSampler(); samplerErr != nil {
+		err = multierr.Append(err, samplerErr)
+	}
+	if _, bucketsErr := durationBuckets(); bucketsErr != nil {
+		err = multierr.Append(err, bucketsErr)
+	}
+	switch exporter := viper.GetString("metrics_exporter"); exporter {
+	case "", metricsExporterPrometheus, metricsExporterOtlpGrpc, metricsExporterOtlpHttp:
+	default:
+		err = multierr.Append(err, fmt.Errorf("unsupported metrics_exporter '%s'", exporter))
+	}
+
+	return err
+}
+
+// configWarnings checks configuration for invalid values server is still able to start with,
+// e.g. invalid log_level is replaced with default level, all found problems are reported at once
+func (h *Handler) configWarnings() error {
+	var err error
+	if _, levelErr := h.configuredLogLevel(); levelErr != nil {
+		err = multierr.Append(err, levelErr)
+	}
+	return err
+}
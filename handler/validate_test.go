@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"github.com/spf13/viper"
+	"go.uber.org/multierr"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateConfig(t *testing.T) {
+	invalid := map[string]any{
+		"tls_cert":           "server.crt",
+		"admin_listen_addr":  ":8081",
+		"listen_addr":        ":8081",
+		"shutdown_timeout":   -time.Second,
+		"trace_sample_ratio": 2,
+		"listen_network":     "udp",
+		"log_level":          "verbose",
+	}
+	for key, value := range invalid {
+		viper.Set(key, value)
+	}
+	defer func() {
+		for key := range invalid {
+			viper.Set(key, nil)
+		}
+	}()
+
+	h := new(Handler)
+	err := h.validateConfig()
+	if err == nil {
+		t.Fatalf("Invalid configuration must result in error")
+	}
+
+	errs := multierr.Errors(err)
+	if len(errs) != 5 {
+		t.Errorf("Expected all 5 problems to be reported, got %d: %s", len(errs), err)
+	}
+
+	for _, key := range []string{"tls_key", "admin_listen_addr", "shutdown_timeout", "trace_sample_ratio", "listen_network"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Error must refer to %s, got: %s", key, err)
+		}
+	}
+
+	// invalid log_level does not prevent server from starting, but is still reported
+	if strings.Contains(err.Error(), "log_level") {
+		t.Errorf("Invalid log_level must be reported as warning, got error: %s", err)
+	}
+	if warnings := h.configWarnings(); warnings == nil || !strings.Contains(warnings.Error(), "log_level") {
+		t.Errorf("Warnings must refer to log_level, got: %v", warnings)
+	}
+}
+
+func TestValidateConfigDefaults(t *testing.T) {
+	h := new(Handler)
+	if err := h.validateConfig(); err != nil {
+		t.Errorf("Default configuration must be valid, got: %s", err)
+	}
+	if warnings := h.configWarnings(); warnings != nil {
+		t.Errorf("Default configuration must have no warnings, got: %s", warnings)
+	}
+}
@@ -0,0 +1,37 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAdminListenAddr(t *testing.T) {
+	h := runConfiguredTestServer(t, ":8084", map[string]any{
+		"admin_listen_addr": ":8085",
+	})
+	defer h.GracefulShutdown("test")
+
+	cases := []struct {
+		url      string
+		expected int
+	}{
+		{url: "http://127.0.0.1:8085/metrics", expected: http.StatusOK},
+		{url: "http://127.0.0.1:8085/readyz", expected: http.StatusOK},
+		{url: "http://127.0.0.1:8085/debug", expected: http.StatusNotFound},
+		{url: "http://127.0.0.1:8084/metrics", expected: http.StatusNotFound},
+		{url: "http://127.0.0.1:8084/readyz", expected: http.StatusNotFound},
+		{url: "http://127.0.0.1:8084/debug", expected: http.StatusOK},
+	}
+
+	for _, c := range cases {
+		res, err := http.Get(c.url)
+		if err != nil {
+			t.Fatalf("Failed to complete request: %s", err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != c.expected {
+			t.Errorf("Expected %d for %s, got: %d", c.expected, c.url, res.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestBasicAuth(t *testing.T) {
+	setTestSettings(t, map[string]any{
+		"auth_username": "busybox",
+		"auth_password": "secret",
+	})
+
+	cases := []struct {
+		path     string
+		username string
+		password string
+		expected int
+	}{
+		{path: "/debug", expected: http.StatusUnauthorized},
+		{path: "/debug", username: "busybox", password: "wrong", expected: http.StatusUnauthorized},
+		{path: "/debug", username: "busybox", password: "secret", expected: http.StatusOK},
+		{path: "/metrics", expected: http.StatusUnauthorized},
+		{path: "/health", expected: http.StatusOK},
+	}
+
+	for _, c := range cases {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:8081"+c.path, nil)
+		if err != nil {
+			t.Fatalf("Unable to create request: %s", err)
+		}
+		if len(c.username) > 0 {
+			req.SetBasicAuth(c.username, c.password)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to complete request: %s", err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != c.expected {
+			t.Errorf("%s: expected status %d, got %d", c.path, c.expected, res.StatusCode)
+		}
+
+		if res.StatusCode == http.StatusUnauthorized && len(res.Header.Get("WWW-Authenticate")) == 0 {
+			t.Errorf("%s: WWW-Authenticate header is missing", c.path)
+		}
+	}
+}
+
+func TestBearerAuth(t *testing.T) {
+	setTestSettings(t, map[string]any{
+		"auth_bearer_token": "first,second",
+	})
+
+	cases := []struct {
+		authorization string
+		expected      int
+	}{
+		{expected: http.StatusUnauthorized},
+		{authorization: "Bearer wrong", expected: http.StatusUnauthorized},
+		{authorization: "Bearer first", expected: http.StatusOK},
+		{authorization: "Bearer second", expected: http.StatusOK},
+	}
+
+	for _, c := range cases {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:8081/debug", nil)
+		if err != nil {
+			t.Fatalf("Unable to create request: %s", err)
+		}
+		if len(c.authorization) > 0 {
+			req.Header.Set("Authorization", c.authorization)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to complete request: %s", err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != c.expected {
+			t.Errorf("%q: expected status %d, got %d", c.authorization, c.expected, res.StatusCode)
+		}
+	}
+}
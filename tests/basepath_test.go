@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestBasePath(t *testing.T) {
+	h := runConfiguredTestServer(t, ":8084", map[string]any{
+		"base_path": "tools/busybox/",
+	})
+	defer h.GracefulShutdown("test")
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	cases := []struct {
+		path     string
+		expected int
+		location string
+	}{
+		{path: "/tools/busybox/health", expected: http.StatusOK},
+		{path: "/tools/busybox/debug", expected: http.StatusOK},
+		{path: "/debug", expected: http.StatusNotFound},
+		{path: "/tools/busybox/redirect/2", expected: http.StatusFound, location: "/tools/busybox/redirect/1"},
+		{
+			path:     "/tools/busybox/redirect/1?absolute=true",
+			expected: http.StatusFound,
+			location: "http://127.0.0.1:8084/tools/busybox/redirect/0?absolute=true",
+		},
+		{path: "/tools/busybox/cookies/set?name=value", expected: http.StatusFound, location: "/tools/busybox/cookies"},
+	}
+
+	for _, c := range cases {
+		res, err := client.Get("http://127.0.0.1:8084" + c.path)
+		if err != nil {
+			t.Fatalf("Failed to complete request: %s", err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != c.expected {
+			t.Errorf("Expected %d for %s, got: %d", c.expected, c.path, res.StatusCode)
+		}
+		if location := res.Header.Get("Location"); location != c.location {
+			t.Errorf("Expected location '%s' for %s, got: '%s'", c.location, c.path, location)
+		}
+	}
+
+	res, err := client.Get("http://127.0.0.1:8084/tools/busybox/anything/nested/path")
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	defer res.Body.Close()
+
+	var result struct {
+		PathSuffix string `json:"path_suffix"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		t.Fatalf("Unable to unmarshal request response: %s", err)
+	}
+	if result.PathSuffix != "nested/path" {
+		t.Errorf("Expected path suffix relative to base path, got: '%s'", result.PathSuffix)
+	}
+}
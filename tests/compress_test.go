@@ -0,0 +1,267 @@
+package tests
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"github.com/rovergulf/busybox/handler"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestResponseCompression(t *testing.T) {
+	h := runConfiguredTestServer(t, ":8084", map[string]any{
+		"enable_compression":    true,
+		"compression_min_bytes": 256,
+	})
+	defer h.GracefulShutdown("test")
+
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:8084/headers", nil)
+	if err != nil {
+		t.Fatalf("Unable to create request: %s", err)
+	}
+	// transport does not decompress response if Accept-Encoding is set explicitly
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("X-Padding", strings.Repeat("busybox", 64))
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	defer res.Body.Close()
+
+	if encoding := res.Header.Get("Content-Encoding"); encoding != "gzip" {
+		t.Fatalf("Expected gzip content encoding, got: %q", encoding)
+	}
+	if contentType := res.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+		t.Errorf("Expected JSON content type to be preserved, got: %q", contentType)
+	}
+
+	reader, err := gzip.NewReader(res.Body)
+	if err != nil {
+		t.Fatalf("Unable to read gzip response: %s", err)
+	}
+
+	var result struct {
+		Headers map[string][]string `json:"headers"`
+	}
+	if err := json.NewDecoder(reader).Decode(&result); err != nil {
+		t.Fatalf("Unable to unmarshal request response: %s", err)
+	}
+	if len(result.Headers["X-Padding"]) != 1 {
+		t.Errorf("Invalid decompressed response: %v", result.Headers)
+	}
+
+	// small responses are left uncompressed
+	req, err = http.NewRequest(http.MethodGet, "http://127.0.0.1:8084/ip", nil)
+	if err != nil {
+		t.Fatalf("Unable to create request: %s", err)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	res.Body.Close()
+
+	if encoding := res.Header.Get("Content-Encoding"); len(encoding) > 0 {
+		t.Errorf("Small response must not be compressed, got: %q", encoding)
+	}
+}
+
+func TestResponseCompressionDeflate(t *testing.T) {
+	// compression settings are applied on reload
+	setTestSettings(t, map[string]any{
+		"enable_compression":    true,
+		"compression_min_bytes": 256,
+	})
+
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:8081/headers", nil)
+	if err != nil {
+		t.Fatalf("Unable to create request: %s", err)
+	}
+	req.Header.Set("Accept-Encoding", "deflate")
+	req.Header.Set("X-Padding", strings.Repeat("busybox", 64))
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	defer res.Body.Close()
+
+	if encoding := res.Header.Get("Content-Encoding"); encoding != "deflate" {
+		t.Fatalf("Expected deflate content encoding, got: %q", encoding)
+	}
+
+	// zlib reader fails on raw DEFLATE stream, as it lacks zlib header
+	reader, err := zlib.NewReader(res.Body)
+	if err != nil {
+		t.Fatalf("Unable to read zlib wrapped response: %s", err)
+	}
+
+	var result struct {
+		Headers map[string][]string `json:"headers"`
+	}
+	if err := json.NewDecoder(reader).Decode(&result); err != nil {
+		t.Fatalf("Unable to unmarshal request response: %s", err)
+	}
+	if len(result.Headers["X-Padding"]) != 1 {
+		t.Errorf("Invalid decompressed response: %v", result.Headers)
+	}
+}
+
+func TestRequestDecompression(t *testing.T) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	writer.Write([]byte(`{"message":"compressed"}`))
+	writer.Close()
+
+	req, err := http.NewRequest(http.MethodPost, "http://127.0.0.1:8081/debug", &buf)
+	if err != nil {
+		t.Fatalf("Unable to create request: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	defer res.Body.Close()
+
+	var result map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		t.Fatalf("Unable to unmarshal request response: %s", err)
+	}
+
+	if result["content_encoding"] != "gzip" {
+		t.Errorf("Invalid content encoding result: %v", result["content_encoding"])
+	}
+	body, ok := result["body"].(map[string]any)
+	if !ok || body["message"] != "compressed" {
+		t.Errorf("Invalid decompressed body result: %v", result)
+	}
+}
+
+func TestRequestDecompressionLimit(t *testing.T) {
+	setTestSettings(t, map[string]any{
+		"max_body_bytes": 1024,
+	})
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	writer.Write(bytes.Repeat([]byte("0"), 1<<20))
+	writer.Close()
+
+	req, err := http.NewRequest(http.MethodPost, "http://127.0.0.1:8081/debug", &buf)
+	if err != nil {
+		t.Fatalf("Unable to create request: %s", err)
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("Content-Encoding", "gzip")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	defer res.Body.Close()
+
+	var result struct {
+		Error struct {
+			Status int   `json:"status"`
+			Limit  int64 `json:"limit"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		t.Fatalf("Unable to unmarshal request response: %s", err)
+	}
+
+	// limit is applied to decompressed body
+	if res.StatusCode != http.StatusRequestEntityTooLarge || result.Error.Limit != 1024 {
+		t.Errorf("Decompressed body over max_body_bytes must be rejected with 413 and limit, got %d: %+v", res.StatusCode, result)
+	}
+}
+
+func TestGzipRoute(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:8081/gzip", nil)
+	if err != nil {
+		t.Fatalf("Unable to create request: %s", err)
+	}
+	// response must be compressed even if client does not accept it
+	req.Header.Set("Accept-Encoding", "identity")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	defer res.Body.Close()
+
+	if encoding := res.Header.Get("Content-Encoding"); encoding != "gzip" {
+		t.Fatalf("Expected gzip content encoding, got: %q", encoding)
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("Unable to read response: %s", err)
+	}
+	if res.ContentLength != int64(len(data)) {
+		t.Errorf("Expected Content-Length %d, got: %d", len(data), res.ContentLength)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Unable to read gzip response: %s", err)
+	}
+
+	var result handler.EchoResponse
+	if err := json.NewDecoder(reader).Decode(&result); err != nil {
+		t.Fatalf("Unable to unmarshal request response: %s", err)
+	}
+	if !result.Gzipped || result.Method != http.MethodGet {
+		t.Errorf("Invalid gzip echo response: %+v", result)
+	}
+}
+
+func TestDeflateRoute(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:8081/deflate", nil)
+	if err != nil {
+		t.Fatalf("Unable to create request: %s", err)
+	}
+	req.Header.Set("Accept-Encoding", "identity")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	defer res.Body.Close()
+
+	if encoding := res.Header.Get("Content-Encoding"); encoding != "deflate" {
+		t.Fatalf("Expected deflate content encoding, got: %q", encoding)
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("Unable to read response: %s", err)
+	}
+	if res.ContentLength != int64(len(data)) {
+		t.Errorf("Expected Content-Length %d, got: %d", len(data), res.ContentLength)
+	}
+
+	// zlib reader fails on raw DEFLATE stream, as it lacks zlib header
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Unable to read zlib wrapped response: %s", err)
+	}
+
+	var result handler.EchoResponse
+	if err := json.NewDecoder(reader).Decode(&result); err != nil {
+		t.Fatalf("Unable to unmarshal request response: %s", err)
+	}
+	if !result.Deflated || result.Gzipped {
+		t.Errorf("Invalid deflate echo response: %+v", result)
+	}
+}
@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	h := runConfiguredTestServer(t, ":8084", map[string]any{
+		"max_concurrent_requests": 1,
+	})
+	defer h.GracefulShutdown("test")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		res, err := http.Get("http://127.0.0.1:8084/delay/500ms")
+		if err != nil {
+			t.Errorf("Failed to complete request: %s", err)
+			return
+		}
+		res.Body.Close()
+	}()
+	// let delayed request take the only slot
+	time.Sleep(100 * time.Millisecond)
+
+	res, err := http.Get("http://127.0.0.1:8084/debug")
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable || res.Header.Get("Retry-After") != "1" {
+		t.Errorf("Expected %d with Retry-After, got: %d %v", http.StatusServiceUnavailable, res.StatusCode, res.Header)
+	}
+
+	res, err = http.Get("http://127.0.0.1:8084/health")
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Health check must bypass concurrency limit, got: %d", res.StatusCode)
+	}
+
+	<-done
+	res, err = http.Get("http://127.0.0.1:8084/debug")
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected %d once slot is released, got: %d", http.StatusOK, res.StatusCode)
+	}
+}
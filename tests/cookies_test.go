@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+)
+
+func getCookies(t *testing.T, client *http.Client, url string) map[string]string {
+	res, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	defer res.Body.Close()
+
+	var result struct {
+		Cookies map[string]string `json:"cookies"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		t.Fatalf("Unable to unmarshal request response: %s", err)
+	}
+	return result.Cookies
+}
+
+func TestCookies(t *testing.T) {
+	setTestSettings(t, map[string]any{
+		"redact_cookies": []string{"session"},
+	})
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatalf("Unable to create cookie jar: %s", err)
+	}
+	client := &http.Client{Jar: jar}
+
+	cookies := getCookies(t, client, "http://127.0.0.1:8081/cookies/set?flavor=oatmeal&session=secret")
+	if cookies["flavor"] != "oatmeal" {
+		t.Errorf("Expected flavor cookie to be set, got: %v", cookies)
+	}
+	if cookies["session"] != "***" {
+		t.Errorf("Expected session cookie to be redacted, got: %v", cookies)
+	}
+
+	cookies = getCookies(t, client, "http://127.0.0.1:8081/cookies/delete?flavor")
+	if _, ok := cookies["flavor"]; ok {
+		t.Errorf("Expected flavor cookie to be deleted, got: %v", cookies)
+	}
+	if _, ok := cookies["session"]; !ok {
+		t.Errorf("Expected session cookie to be kept, got: %v", cookies)
+	}
+}
+
+func TestCookiesDefaultConfig(t *testing.T) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatalf("Unable to create cookie jar: %s", err)
+	}
+	client := &http.Client{Jar: jar}
+
+	// Cookie header is redacted by default, cookie values are echoed unless named by redact_cookies
+	cookies := getCookies(t, client, "http://127.0.0.1:8081/cookies/set?flavor=oatmeal")
+	if cookies["flavor"] != "oatmeal" {
+		t.Errorf("Expected flavor cookie to be echoed, got: %v", cookies)
+	}
+
+	cookies = getCookies(t, client, "http://127.0.0.1:8081/cookies")
+	if cookies["flavor"] != "oatmeal" {
+		t.Errorf("Expected flavor cookie to be kept, got: %v", cookies)
+	}
+}
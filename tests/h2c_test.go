@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"golang.org/x/net/http2"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestH2C(t *testing.T) {
+	h := runConfiguredTestServer(t, ":8084", map[string]any{
+		"enable_h2c": true,
+	})
+	defer h.GracefulShutdown("test")
+
+	h2cClient := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			},
+		},
+	}
+
+	cases := []struct {
+		client *http.Client
+		proto  string
+		major  float64
+		minor  float64
+	}{
+		{client: h2cClient, proto: "HTTP/2.0", major: 2, minor: 0},
+		{client: http.DefaultClient, proto: "HTTP/1.1", major: 1, minor: 1},
+	}
+
+	for _, c := range cases {
+		res, err := c.client.Get("http://127.0.0.1:8084/debug")
+		if err != nil {
+			t.Fatalf("Failed to complete %s request: %s", c.proto, err)
+		}
+
+		var result map[string]any
+		err = json.NewDecoder(res.Body).Decode(&result)
+		res.Body.Close()
+		if err != nil {
+			t.Fatalf("Unable to unmarshal request response: %s", err)
+		}
+
+		if result["proto"] != c.proto {
+			t.Errorf("Expected %s protocol, got: %v", c.proto, result["proto"])
+		}
+		if result["proto_major"] != c.major || result["proto_minor"] != c.minor {
+			t.Errorf("Expected %s protocol version, got: %v.%v", c.proto, result["proto_major"], result["proto_minor"])
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func scrapeMetrics(t *testing.T, addr string) string {
+	res, err := http.Get("http://127.0.0.1" + addr + "/metrics")
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("Unable to read metrics: %s", err)
+	}
+
+	return string(data)
+}
+
+func TestRequestDurationMetric(t *testing.T) {
+	res, err := http.Get("http://127.0.0.1:8081/status/404")
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	res.Body.Close()
+
+	metrics := scrapeMetrics(t, ":8081")
+	expected := `busybox_http_request_duration_seconds_count{method="GET",route="/status/{codes}",status="404"}`
+	if !strings.Contains(metrics, expected) {
+		t.Errorf("Request duration metric is not recorded by route")
+	}
+}
+
+func TestRequestsTotalMetric(t *testing.T) {
+	res, err := http.Get("http://127.0.0.1:8081/status/503")
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	res.Body.Close()
+
+	metrics := scrapeMetrics(t, ":8081")
+	expected := `busybox_http_requests_total{method="GET",route="/status/{codes}",status_class="5xx"}`
+	if !strings.Contains(metrics, expected) {
+		t.Errorf("Requests counter is not recorded by status class")
+	}
+}
+
+func TestResponseSizeMetric(t *testing.T) {
+	res, err := http.Get("http://127.0.0.1:8081/bytes/5000")
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	metrics := scrapeMetrics(t, ":8081")
+	expected := `busybox_http_response_size_bytes_bucket{route="/bytes/{n}",le="10000"}`
+	if !strings.Contains(metrics, expected) {
+		t.Errorf("Response size metric is not recorded by route")
+	}
+}
+
+func TestRequestBodySizeMetric(t *testing.T) {
+	res, err := http.Post("http://127.0.0.1:8081/echo", "text/plain", strings.NewReader(strings.Repeat("x", 500)))
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	metrics := scrapeMetrics(t, ":8081")
+	expected := `busybox_request_body_bytes_bucket{route="/echo",le="1000"} 1`
+	if !strings.Contains(metrics, expected) {
+		t.Errorf("Request body size metric is not recorded by route, expected %s", expected)
+	}
+}
+
+func TestBuildInfoMetric(t *testing.T) {
+	metrics := scrapeMetrics(t, ":8081")
+	if !strings.Contains(metrics, "busybox_build_info{") || !strings.Contains(metrics, `go_version="go`) {
+		t.Errorf("Build info metric is not registered")
+	}
+}
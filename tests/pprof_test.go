@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPprofAuth(t *testing.T) {
+	h := runConfiguredTestServer(t, ":8084", map[string]any{
+		"enable_profiling":  true,
+		"auth_bearer_token": "secret",
+	})
+	defer h.GracefulShutdown("test")
+
+	cases := []struct {
+		token    string
+		expected int
+	}{
+		{expected: http.StatusUnauthorized},
+		{token: "secret", expected: http.StatusOK},
+	}
+
+	for _, c := range cases {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:8084/debug/pprof/heap", nil)
+		if err != nil {
+			t.Fatalf("Unable to create request: %s", err)
+		}
+		if len(c.token) > 0 {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to complete request: %s", err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != c.expected {
+			t.Errorf("Expected %d, got: %d", c.expected, res.StatusCode)
+		}
+	}
+}
+
+func TestPprofListenAddr(t *testing.T) {
+	h := runConfiguredTestServer(t, ":8084", map[string]any{
+		"enable_profiling":  true,
+		"pprof_listen_addr": ":8085",
+	})
+	defer h.GracefulShutdown("test")
+
+	cases := []struct {
+		url      string
+		expected int
+	}{
+		{url: "http://127.0.0.1:8085/debug/pprof/", expected: http.StatusOK},
+		{url: "http://127.0.0.1:8084/debug/pprof/", expected: http.StatusNotFound},
+	}
+
+	for _, c := range cases {
+		res, err := http.Get(c.url)
+		if err != nil {
+			t.Fatalf("Failed to complete request: %s", err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != c.expected {
+			t.Errorf("Expected %d for %s, got: %d", c.expected, c.url, res.StatusCode)
+		}
+	}
+}
+
+func TestPprofListenAddrTimeouts(t *testing.T) {
+	h := runConfiguredTestServer(t, ":8084", map[string]any{
+		"enable_profiling":  true,
+		"pprof_listen_addr": ":8085",
+		"write_timeout":     500 * time.Millisecond,
+		"max_header_bytes":  1024,
+	})
+	defer h.GracefulShutdown("test")
+
+	// profile is written for longer than write_timeout, which is not applied to it
+	res, err := http.Get("http://127.0.0.1:8085/debug/pprof/profile?seconds=1")
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	data, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatalf("Unable to read profile: %s", err)
+	}
+	if res.StatusCode != http.StatusOK || len(data) == 0 {
+		t.Errorf("Expected profile longer than write_timeout to be served, got: %d %s", res.StatusCode, data)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:8085/debug/pprof/", nil)
+	if err != nil {
+		t.Fatalf("Unable to create request: %s", err)
+	}
+	req.Header.Set("X-Large", strings.Repeat("x", 16<<10))
+
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf("Expected max_header_bytes to be applied, got: %d", res.StatusCode)
+	}
+}
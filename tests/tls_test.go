@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/rovergulf/busybox/handler"
+	"github.com/spf13/viper"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCert is a certificate issued by parent, or self-signed CA certificate if parent is nil
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCert(t *testing.T, template *x509.Certificate, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unable to generate key: %s", err)
+	}
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	issuer, issuerKey := template, key
+	if parent != nil {
+		issuer, issuerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, issuerKey)
+	if err != nil {
+		t.Fatalf("Unable to create certificate: %s", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Unable to parse certificate: %s", err)
+	}
+
+	return &testCert{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+func (c *testCert) keyPEM(t *testing.T) []byte {
+	der, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatalf("Unable to marshal key: %s", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func writeTestFile(t *testing.T, dir, name string, data []byte) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Unable to write %s: %s", name, err)
+	}
+	return path
+}
+
+func TestTLSClientCert(t *testing.T) {
+	ca := newTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "busybox test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	server := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	client := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "busybox client", Organization: []string{"rovergulf"}},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+
+	dir := t.TempDir()
+	settings := map[string]any{
+		"listen_addr":        ":8084",
+		"tls_cert":           writeTestFile(t, dir, "server.crt", server.pem),
+		"tls_key":            writeTestFile(t, dir, "server.key", server.keyPEM(t)),
+		"tls_client_auth":    "require",
+		"tls_client_ca_file": writeTestFile(t, dir, "ca.crt", ca.pem),
+	}
+	for key, value := range settings {
+		viper.Set(key, value)
+	}
+	defer func() {
+		for key := range settings {
+			viper.Set(key, nil)
+		}
+	}()
+
+	h := new(handler.Handler)
+	go func() {
+		if err := h.Run(); err != nil {
+			t.Errorf("Unable to run server: %s", err)
+		}
+	}()
+	defer h.GracefulShutdown("test")
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientCert, err := tls.X509KeyPair(client.pem, client.keyPEM(t))
+	if err != nil {
+		t.Fatalf("Unable to load client certificate: %s", err)
+	}
+	tlsClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{clientCert},
+	}}}
+
+	var res *http.Response
+	for i := 0; i < 50; i++ {
+		if res, err = tlsClient.Get("https://127.0.0.1:8084/debug"); err == nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+
+	var result map[string]any
+	err = json.NewDecoder(res.Body).Decode(&result)
+	res.Body.Close()
+	if err != nil {
+		t.Fatalf("Unable to unmarshal request response: %s", err)
+	}
+
+	tlsResult, _ := result["tls"].(map[string]any)
+	if subject := client.cert.Subject.String(); tlsResult["client_cert_subject"] != subject {
+		t.Errorf("Expected client certificate subject '%s', got: %v", subject, tlsResult["client_cert_subject"])
+	}
+
+	// client certificate is required, so handshake without it is rejected
+	noCertClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	if res, err := noCertClient.Get("https://127.0.0.1:8084/debug"); err == nil {
+		res.Body.Close()
+		t.Errorf("Expected request without client certificate to be rejected")
+	}
+}
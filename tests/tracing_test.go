@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestTraceContextPropagation(t *testing.T) {
+	h := runConfiguredTestServer(t, ":8082", map[string]any{
+		"trace_exporter": "otlp-http",
+		"otlp_endpoint":  "http://127.0.0.1:4318",
+	})
+	defer h.GracefulShutdown("test")
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:8082/debug", nil)
+	if err != nil {
+		t.Fatalf("Unable to create request: %s", err)
+	}
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to complete request: %s", err)
+	}
+
+	var result map[string]any
+	decoder := json.NewDecoder(res.Body)
+	if err := decoder.Decode(&result); err != nil {
+		t.Errorf("Unable to unmarshal request response")
+	}
+
+	if result["trace_id"] != traceID {
+		t.Errorf("Request span must continue incoming trace %s, got: %v", traceID, result["trace_id"])
+	}
+
+	if result["span_id"] == "00f067aa0ba902b7" {
+		t.Errorf("Request span must be a child of incoming span")
+	}
+}